@@ -16,6 +16,9 @@ import (
 
 type Service interface {
 	Health() map[string]string
+
+	// Client returns the Redis client, for the services that store data.
+	Client() *redis.Client
 }
 
 type service struct {
@@ -53,6 +56,10 @@ func New() Service {
 	return s
 }
 
+func (s *service) Client() *redis.Client {
+	return s.db
+}
+
 // Health returns the health status and statistics of the Redis server.
 func (s *service) Health() map[string]string {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second) // Default is now 5s
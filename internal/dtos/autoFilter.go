@@ -14,4 +14,17 @@ type AutoFilterResponse struct {
 	Price    float64 `json:"price"`
 	URL      string  `json:"url"`
 	ImageURL string  `json:"image_url"`
+	// ImageURLs are all the images of the listing, ImageURL first.
+	ImageURLs []string `json:"image_urls"`
+	// Brand, Model, Year and Kilometers are only set by the sources that
+	// publish them as structured attributes.
+	Brand      string `json:"brand,omitempty"`
+	Model      string `json:"model,omitempty"`
+	Year       uint32 `json:"year,omitempty"`
+	Kilometers uint32 `json:"kilometers,omitempty"`
+	// CachedImageURL, CachedImageURLs and ThumbnailURL are the images served
+	// by the service itself, set when the image cache is enabled.
+	CachedImageURL  string   `json:"cached_image_url,omitempty"`
+	CachedImageURLs []string `json:"cached_image_urls,omitempty"`
+	ThumbnailURL    string   `json:"thumbnail_url,omitempty"`
 }
@@ -5,12 +5,48 @@ type ScrapperType int
 const (
 	_ ScrapperType = iota
 	NeoAuto
+	MercadoLibre
+	Imported
 )
 
 var ScrapperTypeNames = map[ScrapperType]string{
-	NeoAuto: "NeoAuto",
+	NeoAuto:      "NeoAuto",
+	MercadoLibre: "MercadoLibre",
+	Imported:     "Imported",
 }
 
 func (s ScrapperType) String() string {
 	return ScrapperTypeNames[s]
-}
\ No newline at end of file
+}
+
+// ParseScrapperType returns the ScrapperType registered under name.
+func ParseScrapperType(name string) (ScrapperType, bool) {
+	for s, n := range ScrapperTypeNames {
+		if n == name {
+			return s, true
+		}
+	}
+
+	return 0, false
+}
+
+// RegisterScrapperType returns the ScrapperType registered under name, adding
+// a new one when the name is unknown. It is meant to be called at startup,
+// before any concurrent use, to register sources declared by site profiles.
+func RegisterScrapperType(name string) ScrapperType {
+	if s, ok := ParseScrapperType(name); ok {
+		return s
+	}
+
+	var next ScrapperType
+	for s := range ScrapperTypeNames {
+		if s > next {
+			next = s
+		}
+	}
+	next++
+
+	ScrapperTypeNames[next] = name
+
+	return next
+}
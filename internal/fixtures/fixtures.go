@@ -0,0 +1,105 @@
+// Package fixtures serves recorded marketplace pages from a local directory
+// so scrapers can run without network access.
+package fixtures
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	indexName    = "index"
+	responsesDir = "responses"
+)
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._=-]+`)
+
+// Key returns the fixture file name of a page URL. Only the path and query
+// are used, so a page recorded from production can be served from a local
+// server.
+func Key(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return unsafeChars.ReplaceAllString(rawURL, "_") + ".html"
+	}
+
+	key := strings.Trim(u.EscapedPath(), "/")
+	if key == "" {
+		key = indexName
+	}
+
+	if u.RawQuery != "" {
+		key += "_" + u.RawQuery
+	}
+
+	return unsafeChars.ReplaceAllString(key, "_") + ".html"
+}
+
+// JSONKey returns the fixture file name of a JSON API URL, like Key.
+func JSONKey(rawURL string) string {
+	return strings.TrimSuffix(Key(rawURL), ".html") + ".json"
+}
+
+// ResponseKey returns the fixture file name of a network response recorded
+// while rendering a page. Responses usually come from other hosts, so the
+// host is part of the key.
+func ResponseKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return filepath.Join(responsesDir, unsafeChars.ReplaceAllString(rawURL, "_"))
+	}
+
+	key := u.Host + "_" + strings.Trim(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		key += "_" + u.RawQuery
+	}
+
+	return filepath.Join(responsesDir, unsafeChars.ReplaceAllString(key, "_"))
+}
+
+// Write stores a fixture under dir, creating the directories it needs.
+func Write(dir string, name string, data []byte) error {
+	path := filepath.Join(dir, name)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Read returns the fixture stored under dir.
+func Read(dir string, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(dir, name))
+}
+
+// Handler serves the page or JSON fixture of every request URL from dir,
+// falling back to the fixture of the path alone when the query has none.
+// Unknown URLs get a 404.
+func Handler(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uri := r.URL.RequestURI()
+
+		for _, name := range []string{Key(uri), JSONKey(uri), Key(r.URL.Path), JSONKey(r.URL.Path)} {
+			data, err := Read(dir, name)
+			if err != nil {
+				continue
+			}
+
+			contentType := "text/html; charset=utf-8"
+			if filepath.Ext(name) == ".json" {
+				contentType = "application/json"
+			}
+
+			w.Header().Set("Content-Type", contentType)
+			w.Write(data)
+			return
+		}
+
+		http.NotFound(w, r)
+	})
+}
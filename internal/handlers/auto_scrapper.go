@@ -2,6 +2,12 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/diegoafg1009/auto-radar-scraping-microservice/pkg/genproto/autoscrapper/v1/autoscrapperv1connect"
 
@@ -12,46 +18,451 @@ import (
 	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
 
 	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// errorDomain is the domain of the ErrorInfo details of the service.
+const errorDomain = "autoscrapper.v1"
+
+// errorReasons are the ErrorInfo reasons of the scrape error classes.
+var errorReasons = map[string]string{
+	services.ErrorClassBlocked:            "SOURCE_BLOCKED",
+	services.ErrorClassLayoutChanged:      "LAYOUT_CHANGED",
+	services.ErrorClassBrowserUnavailable: "BROWSER_UNAVAILABLE",
+	services.ErrorClassNoProxy:            "NO_PROXY_AVAILABLE",
+	services.ErrorClassTimeout:            "SOURCE_TIMEOUT",
+	services.ErrorClassFetch:              "SOURCE_UNREACHABLE",
+	services.ErrorClassUnknown:            "SCRAPE_FAILED",
+}
+
 type AutoScrapperHandler struct {
 	autoscrapperv1connect.UnimplementedAutoScrapperServiceHandler
 	autoscrapper services.AutoScrapper
+	quality      *services.Quality
+	runs         services.RunLog
+	imports      *services.ImportSource
+	lifecycle    *services.Lifecycle
+	catalog      *services.Catalog
+	suggester    *services.VehicleSuggester
 }
 
-func NewAutoScrapperHandler(autoscrapper services.AutoScrapper) *AutoScrapperHandler {
+func NewAutoScrapperHandler(autoscrapper services.AutoScrapper, quality *services.Quality, runs services.RunLog, imports *services.ImportSource, lifecycle *services.Lifecycle, catalog *services.Catalog, suggester *services.VehicleSuggester) *AutoScrapperHandler {
 	return &AutoScrapperHandler{
 		autoscrapper: autoscrapper,
+		quality:      quality,
+		runs:         runs,
+		imports:      imports,
+		lifecycle:    lifecycle,
+		catalog:      catalog,
+		suggester:    suggester,
 	}
 }
 
 func (h *AutoScrapperHandler) FindByFilter(ctx context.Context, req *connect.Request[v1.FindByFilterRequest]) (*connect.Response[v1.FindByFilterResponse], error) {
-
-	filter := dtos.AutoFilter{
+	filter, err := services.CanonicalFilter(dtos.AutoFilter{
 		Brand:    req.Msg.Brand,
 		Model:    req.Msg.Model,
 		MinYear:  &req.Msg.MinYear,
 		MaxYear:  &req.Msg.MaxYear,
 		MinPrice: &req.Msg.MinPrice,
 		MaxPrice: &req.Msg.MaxPrice,
+	})
+	if err != nil {
+		return nil, invalidArgument(err)
 	}
 
-	autos, _ := h.autoscrapper.FindByFilter(filter)
+	ctx, emptyScrapes := services.WithEmptyScrapes(ctx)
+
+	autos, err := h.autoscrapper.FindByFilter(ctx, filter)
+	if err != nil {
+		log.Printf("FindByFilter of %q failed: %v", services.NormalizeFilter(filter), err)
+
+		if !errors.Is(err, services.ErrPartialResults) {
+			return nil, scrapeFailed(err)
+		}
+	}
 
 	var autosResponse []*v1.Auto
 
 	for _, auto := range autos {
 		autosResponse = append(autosResponse, &v1.Auto{
-			Title:    auto.Title,
-			Price:    auto.Price,
-			Url:      auto.URL,
-			ImageUrl: auto.ImageURL,
+			Title:      auto.Title,
+			Price:      auto.Price,
+			Url:        auto.URL,
+			ImageUrl:   auto.ImageURL,
+			ImageUrls:  auto.ImageURLs,
+			Brand:      auto.Brand,
+			Model:      auto.Model,
+			Year:       auto.Year,
+			Kilometers: auto.Kilometers,
+
+			CachedImageUrl:  auto.CachedImageURL,
+			CachedImageUrls: auto.CachedImageURLs,
+			ThumbnailUrl:    auto.ThumbnailURL,
 		})
 	}
 
 	response := &v1.FindByFilterResponse{
-		Autos: autosResponse,
+		Autos:    autosResponse,
+		Failures: sourceFailures(err),
+	}
+
+	for _, empty := range emptyScrapes() {
+		response.EmptyScrapes = append(response.EmptyScrapes, &v1.EmptyScrape{
+			Source:   empty.Source,
+			ScrapeId: empty.ScrapeID,
+		})
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (h *AutoScrapperHandler) GetScrapeQuality(ctx context.Context, req *connect.Request[v1.GetScrapeQualityRequest]) (*connect.Response[v1.GetScrapeQualityResponse], error) {
+	response := &v1.GetScrapeQualityResponse{}
+
+	for _, source := range h.quality.Sources() {
+		if req.Msg.Source != "" && req.Msg.Source != source.Source {
+			continue
+		}
+
+		sourceQuality := &v1.SourceQuality{
+			Source:              source.Source,
+			Scrapes:             uint64(source.Scrapes),
+			Seen:                uint64(source.Seen),
+			Extracted:           uint64(source.Extracted),
+			ExtractionRatio:     source.Ratio(),
+			LastExtractionRatio: source.LastRatio,
+			Alerting:            source.Alerting,
+			Alerts:              uint64(source.Alerts),
+		}
+
+		for _, field := range slices.Sorted(maps.Keys(source.Fields)) {
+			outcomes := source.Fields[field]
+
+			sourceQuality.Fields = append(sourceQuality.Fields, &v1.FieldQuality{
+				Field:   field,
+				Found:   uint64(outcomes[services.FieldFound]),
+				Missing: uint64(outcomes[services.FieldMissing]),
+				Invalid: uint64(outcomes[services.FieldInvalid]),
+			})
+		}
+
+		for _, reason := range slices.Sorted(maps.Keys(source.Skipped)) {
+			sourceQuality.Skipped = append(sourceQuality.Skipped, &v1.SkippedItems{
+				Reason: reason,
+				Count:  uint64(source.Skipped[reason]),
+			})
+		}
+
+		response.Sources = append(response.Sources, sourceQuality)
+	}
+
+	if req.Msg.Source != "" && len(response.Sources) == 0 {
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("no scrapes of source %q", req.Msg.Source))
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (h *AutoScrapperHandler) ListScrapeRuns(ctx context.Context, req *connect.Request[v1.ListScrapeRunsRequest]) (*connect.Response[v1.ListScrapeRunsResponse], error) {
+	runs, err := h.runs.List(ctx, services.RunQuery{
+		Source:     req.Msg.Source,
+		ErrorClass: req.Msg.ErrorClass,
+		Limit:      int(req.Msg.Limit),
+	})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnavailable, err)
+	}
+
+	response := &v1.ListScrapeRunsResponse{}
+
+	for _, run := range runs {
+		response.Runs = append(response.Runs, &v1.ScrapeRun{
+			Id:          run.ID,
+			Source:      run.Source,
+			Backend:     run.Backend,
+			Filter:      run.Filter,
+			Url:         run.URL,
+			StartedAt:   timestamppb.New(run.StartedAt),
+			EndedAt:     timestamppb.New(run.EndedAt),
+			Pages:       uint32(run.Pages),
+			Seen:        uint32(run.Seen),
+			Found:       uint32(run.Found),
+			Skipped:     uint32(run.Skipped),
+			Bytes:       uint64(run.Bytes),
+			Proxy:       run.Proxy,
+			Fingerprint: run.Fingerprint,
+			ErrorClass:  run.ErrorClass,
+			Error:       run.Error,
+			ArtifactsId: run.ArtifactsID,
+		})
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (h *AutoScrapperHandler) ImportListings(ctx context.Context, stream *connect.ClientStream[v1.ImportListingsRequest]) (*connect.Response[v1.ImportListingsResponse], error) {
+	var listings []*dtos.AutoFilterResponse
+
+	for stream.Receive() {
+		if len(listings) == services.MaxImportListings {
+			return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("imports hold at most %d listings", services.MaxImportListings))
+		}
+
+		auto := stream.Msg().GetAuto()
+
+		listings = append(listings, &dtos.AutoFilterResponse{
+			Title:      auto.GetTitle(),
+			Price:      auto.GetPrice(),
+			URL:        auto.GetUrl(),
+			ImageURL:   auto.GetImageUrl(),
+			ImageURLs:  auto.GetImageUrls(),
+			Brand:      auto.GetBrand(),
+			Model:      auto.GetModel(),
+			Year:       auto.GetYear(),
+			Kilometers: auto.GetKilometers(),
+		})
+	}
+
+	if err := stream.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := h.imports.Import(ctx, listings)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnavailable, err)
+	}
+
+	response := &v1.ImportListingsResponse{
+		Received:   uint32(result.Received),
+		Imported:   uint32(result.Imported),
+		Updated:    uint32(result.Updated),
+		Duplicates: uint32(result.Duplicates),
+	}
+
+	for _, rejection := range result.Rejected {
+		response.Rejected = append(response.Rejected, &v1.ImportRejection{
+			Index:  uint32(rejection.Index),
+			Url:    rejection.URL,
+			Reason: rejection.Reason,
+		})
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (h *AutoScrapperHandler) ListRemovedListings(ctx context.Context, req *connect.Request[v1.ListRemovedListingsRequest]) (*connect.Response[v1.ListRemovedListingsResponse], error) {
+	listings, err := h.lifecycle.Removed(ctx, services.RemovedQuery{
+		Source: req.Msg.Source,
+		Limit:  int(req.Msg.Limit),
+	})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnavailable, err)
+	}
+
+	response := &v1.ListRemovedListingsResponse{}
+
+	for _, listing := range listings {
+		response.Listings = append(response.Listings, &v1.ListingLifecycle{
+			Url:          listing.URL,
+			Source:       listing.Source,
+			Title:        listing.Title,
+			Price:        listing.Price,
+			Status:       listing.Status,
+			FirstSeen:    timestamppb.New(listing.FirstSeen),
+			LastSeen:     timestamppb.New(listing.LastSeen),
+			RemovedAt:    timestamppb.New(listing.RemovedAt),
+			DaysOnMarket: uint32(listing.DaysOnMarket()),
+		})
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (h *AutoScrapperHandler) ListBrands(ctx context.Context, req *connect.Request[v1.ListBrandsRequest]) (*connect.Response[v1.ListBrandsResponse], error) {
+	response := &v1.ListBrandsResponse{}
+
+	for _, brand := range h.catalog.Brands {
+		response.Brands = append(response.Brands, &v1.VehicleBrand{
+			Name:    brand.Name,
+			Aliases: brand.Aliases,
+			Models:  vehicleModels(brand.Models),
+		})
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (h *AutoScrapperHandler) ListModels(ctx context.Context, req *connect.Request[v1.ListModelsRequest]) (*connect.Response[v1.ListModelsResponse], error) {
+	match, ok := h.catalog.Resolve(req.Msg.Brand, "")
+	if !ok {
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("%w: %q", services.ErrUnknownBrand, req.Msg.Brand))
+	}
+
+	response := &v1.ListModelsResponse{
+		Brand:  match.Brand.Name,
+		Models: vehicleModels(match.Brand.Models),
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+func (h *AutoScrapperHandler) ResolveVehicle(ctx context.Context, req *connect.Request[v1.ResolveVehicleRequest]) (*connect.Response[v1.ResolveVehicleResponse], error) {
+	match, ok := h.catalog.Resolve(req.Msg.Brand, req.Msg.Model)
+	if !ok {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("no catalog brand or model is close enough"))
+	}
+
+	response := &v1.ResolveVehicleResponse{
+		Brand: match.Brand.Name,
+		Score: match.Score,
+	}
+
+	if match.Model != nil {
+		response.Model = match.Model.Name
 	}
 
 	return connect.NewResponse(response), nil
 }
+
+func (h *AutoScrapperHandler) SuggestVehicles(ctx context.Context, req *connect.Request[v1.SuggestVehiclesRequest]) (*connect.Response[v1.SuggestVehiclesResponse], error) {
+	response := &v1.SuggestVehiclesResponse{}
+
+	for _, suggestion := range h.suggester.Suggest(req.Msg.Prefix, int(req.Msg.Limit)) {
+		response.Suggestions = append(response.Suggestions, &v1.VehicleSuggestion{
+			Brand:    suggestion.Brand,
+			Model:    suggestion.Model,
+			Listings: uint32(suggestion.Listings),
+			Score:    suggestion.Score,
+		})
+	}
+
+	return connect.NewResponse(response), nil
+}
+
+// scrapeFailed returns a failed search as an error with an ErrorInfo detail
+// per failed source, carrying the source and scrape ID, and a RetryInfo
+// detail with the shortest wait before a source may answer again. Searches
+// worth retrying are Unavailable, the others Internal.
+func scrapeFailed(err error) *connect.Error {
+	failures := services.SourceErrors(err)
+	if len(failures) == 0 {
+		failures = []*services.SourceError{{Err: err}}
+	}
+
+	var details []proto.Message
+
+	retryable := false
+
+	var retryDelay time.Duration
+
+	for _, failure := range failures {
+		details = append(details, errorInfo(failure))
+
+		if delay, ok := services.RetryDelay(failure.Err); ok && (!retryable || delay < retryDelay) {
+			retryable, retryDelay = true, delay
+		}
+	}
+
+	code := connect.CodeInternal
+
+	if retryable {
+		code = connect.CodeUnavailable
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryDelay)})
+	}
+
+	connectErr := connect.NewError(code, err)
+
+	for _, message := range details {
+		if detail, err := connect.NewErrorDetail(message); err == nil {
+			connectErr.AddDetail(detail)
+		}
+	}
+
+	return connectErr
+}
+
+// sourceFailures returns the failures of the sources of a search that others
+// answered, with the ErrorInfo reason and the RetryInfo delay of each.
+func sourceFailures(err error) []*v1.SourceFailure {
+	var failures []*v1.SourceFailure
+
+	for _, failure := range services.SourceErrors(err) {
+		info := errorInfo(failure)
+
+		sourceFailure := &v1.SourceFailure{
+			Source:   failure.Source,
+			Reason:   info.Reason,
+			ScrapeId: info.Metadata["scrape_id"],
+		}
+
+		if delay, ok := services.RetryDelay(failure.Err); ok {
+			sourceFailure.RetryDelay = durationpb.New(delay)
+		}
+
+		failures = append(failures, sourceFailure)
+	}
+
+	return failures
+}
+
+// errorInfo returns the ErrorInfo of the failure of a source, with the source
+// and the scrape ID in its metadata.
+func errorInfo(failure *services.SourceError) *errdetails.ErrorInfo {
+	info := &errdetails.ErrorInfo{
+		Reason:   errorReasons[services.ErrorClass(failure.Err)],
+		Domain:   errorDomain,
+		Metadata: make(map[string]string),
+	}
+
+	if failure.Source != "" {
+		info.Metadata["source"] = failure.Source
+	}
+
+	if scrapeID, ok := services.ScrapeID(failure.Err); ok {
+		info.Metadata["scrape_id"] = scrapeID
+	}
+
+	return info
+}
+
+// invalidArgument returns err as an InvalidArgument error, with the invalid
+// fields of a *services.FilterError in a BadRequest detail.
+func invalidArgument(err error) *connect.Error {
+	connectErr := connect.NewError(connect.CodeInvalidArgument, err)
+
+	var filterErr *services.FilterError
+	if !errors.As(err, &filterErr) {
+		return connectErr
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, violation := range filterErr.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Field,
+			Description: violation.Description,
+		})
+	}
+
+	if detail, err := connect.NewErrorDetail(badRequest); err == nil {
+		connectErr.AddDetail(detail)
+	}
+
+	return connectErr
+}
+
+func vehicleModels(models []*services.VehicleModel) []*v1.VehicleModel {
+	vehicleModels := make([]*v1.VehicleModel, 0, len(models))
+
+	for _, model := range models {
+		vehicleModels = append(vehicleModels, &v1.VehicleModel{
+			Name:    model.Name,
+			Aliases: model.Aliases,
+		})
+	}
+
+	return vehicleModels
+}
@@ -0,0 +1,73 @@
+package interceptors
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+// accessEntry is the access log record of an RPC.
+type accessEntry struct {
+	Procedure  string  `json:"procedure"`
+	RequestID  string  `json:"request_id,omitempty"`
+	Peer       string  `json:"peer"`
+	DurationMS float64 `json:"duration_ms"`
+	// Code is "ok" for RPCs that succeeded.
+	Code  string `json:"code"`
+	Error string `json:"error,omitempty"`
+}
+
+// AccessLog logs every RPC as a JSON record, with its procedure, request ID,
+// peer address, duration and code.
+type AccessLog struct{}
+
+func NewAccessLog() *AccessLog {
+	return &AccessLog{}
+}
+
+func (a *AccessLog) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		start := time.Now()
+
+		res, err := next(ctx, req)
+		logAccess(ctx, req.Spec().Procedure, req.Peer().Addr, start, err)
+
+		return res, err
+	}
+}
+
+func (a *AccessLog) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (a *AccessLog) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		start := time.Now()
+
+		err := next(ctx, conn)
+		logAccess(ctx, conn.Spec().Procedure, conn.Peer().Addr, start, err)
+
+		return err
+	}
+}
+
+func logAccess(ctx context.Context, procedure string, peer string, start time.Time, err error) {
+	entry := accessEntry{
+		Procedure:  procedure,
+		RequestID:  RequestID(ctx),
+		Peer:       peer,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		Code:       "ok",
+	}
+
+	if err != nil {
+		entry.Code = connect.CodeOf(err).String()
+		entry.Error = err.Error()
+	}
+
+	data, _ := json.Marshal(entry)
+	log.Println("rpc", string(data))
+}
@@ -0,0 +1,19 @@
+// Package interceptors holds the Connect interceptors every RPC of the
+// service runs through.
+package interceptors
+
+import (
+	"connectrpc.com/connect"
+)
+
+// Chain returns the interceptors of every service handler, outermost first:
+// request IDs, access logging, timeouts and panic recovery. Recovery is the
+// innermost so the errors of recovered panics are still logged.
+func Chain(timeouts *Timeouts) connect.Option {
+	return connect.WithInterceptors(
+		NewRequestIDs(),
+		NewAccessLog(),
+		timeouts,
+		NewRecovery(),
+	)
+}
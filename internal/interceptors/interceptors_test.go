@@ -0,0 +1,107 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	v1 "github.com/diegoafg1009/auto-radar-scraping-microservice/pkg/genproto/autoscrapper/v1"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/pkg/genproto/autoscrapper/v1/autoscrapperv1connect"
+)
+
+// testHandler panics on ListBrands, waits on FindByFilter until its context
+// is done and answers ListModels with the request ID it got.
+type testHandler struct {
+	autoscrapperv1connect.UnimplementedAutoScrapperServiceHandler
+}
+
+func (h *testHandler) ListBrands(ctx context.Context, req *connect.Request[v1.ListBrandsRequest]) (*connect.Response[v1.ListBrandsResponse], error) {
+	panic("must not be called")
+}
+
+func (h *testHandler) FindByFilter(ctx context.Context, req *connect.Request[v1.FindByFilterRequest]) (*connect.Response[v1.FindByFilterResponse], error) {
+	select {
+	case <-ctx.Done():
+		return nil, connect.NewError(connect.CodeInternal, ctx.Err())
+	case <-time.After(time.Second):
+		return connect.NewResponse(&v1.FindByFilterResponse{}), nil
+	}
+}
+
+func (h *testHandler) ListModels(ctx context.Context, req *connect.Request[v1.ListModelsRequest]) (*connect.Response[v1.ListModelsResponse], error) {
+	return connect.NewResponse(&v1.ListModelsResponse{Brand: RequestID(ctx)}), nil
+}
+
+func newTestClient(t *testing.T) autoscrapperv1connect.AutoScrapperServiceClient {
+	t.Helper()
+
+	timeouts := NewTimeouts(time.Minute, map[string]time.Duration{
+		autoscrapperv1connect.AutoScrapperServiceFindByFilterProcedure: 50 * time.Millisecond,
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle(autoscrapperv1connect.NewAutoScrapperServiceHandler(&testHandler{}, Chain(timeouts)))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return autoscrapperv1connect.NewAutoScrapperServiceClient(srv.Client(), srv.URL)
+}
+
+func TestChainRecoversPanics(t *testing.T) {
+	client := newTestClient(t)
+
+	_, err := client.ListBrands(context.Background(), connect.NewRequest(&v1.ListBrandsRequest{}))
+	if connect.CodeOf(err) != connect.CodeInternal {
+		t.Fatalf("ListBrands() error = %v, want Internal", err)
+	}
+
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) || connectErr.Meta().Get(RequestIDHeader) == "" {
+		t.Errorf("ListBrands() error = %v, want the request ID in its metadata", err)
+	}
+}
+
+func TestChainTimesOut(t *testing.T) {
+	client := newTestClient(t)
+
+	start := time.Now()
+
+	_, err := client.FindByFilter(context.Background(), connect.NewRequest(&v1.FindByFilterRequest{}))
+	if connect.CodeOf(err) != connect.CodeDeadlineExceeded {
+		t.Fatalf("FindByFilter() error = %v, want DeadlineExceeded", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("FindByFilter() took %s, want it to stop at its timeout", elapsed)
+	}
+}
+
+func TestChainPropagatesRequestIDs(t *testing.T) {
+	client := newTestClient(t)
+
+	req := connect.NewRequest(&v1.ListModelsRequest{})
+	req.Header().Set(RequestIDHeader, "abc-123")
+
+	res, err := client.ListModels(context.Background(), req)
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+
+	if res.Msg.Brand != "abc-123" || res.Header().Get(RequestIDHeader) != "abc-123" {
+		t.Errorf("request ID = %q in the handler and %q in the response, want abc-123", res.Msg.Brand, res.Header().Get(RequestIDHeader))
+	}
+
+	res, err = client.ListModels(context.Background(), connect.NewRequest(&v1.ListModelsRequest{}))
+	if err != nil {
+		t.Fatalf("ListModels() error = %v", err)
+	}
+
+	if id := res.Header().Get(RequestIDHeader); id == "" || id != res.Msg.Brand {
+		t.Errorf("generated request ID = %q in the handler and %q in the response, want the same one", res.Msg.Brand, id)
+	}
+}
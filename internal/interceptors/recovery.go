@@ -0,0 +1,55 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+
+	"connectrpc.com/connect"
+)
+
+// errPanicked is what clients see of a handler panic; the panic itself is
+// only logged.
+var errPanicked = errors.New("internal error")
+
+// Recovery turns the panics of handlers, like those of the rod Must methods,
+// into Internal errors instead of crashing the process.
+type Recovery struct{}
+
+func NewRecovery() *Recovery {
+	return &Recovery{}
+}
+
+func (r *Recovery) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (res connect.AnyResponse, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				res, err = nil, recovered(req.Spec().Procedure, p)
+			}
+		}()
+
+		return next(ctx, req)
+	}
+}
+
+func (r *Recovery) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (r *Recovery) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(conn.Spec().Procedure, p)
+			}
+		}()
+
+		return next(ctx, conn)
+	}
+}
+
+func recovered(procedure string, p any) error {
+	log.Printf("%s panicked: %v\n%s", procedure, p, debug.Stack())
+	return connect.NewError(connect.CodeInternal, errPanicked)
+}
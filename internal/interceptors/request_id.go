@@ -0,0 +1,98 @@
+package interceptors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"connectrpc.com/connect"
+)
+
+// RequestIDHeader carries the request ID, in requests and responses.
+const RequestIDHeader = "X-Request-Id"
+
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestID returns the ID of the request of ctx, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDs gives every request an ID, the one in its X-Request-Id header
+// or a new one, available to handlers through RequestID and sent back in the
+// response headers and error metadata.
+type RequestIDs struct{}
+
+func NewRequestIDs() *RequestIDs {
+	return &RequestIDs{}
+}
+
+func (r *RequestIDs) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		id := requestID(req.Header().Get(RequestIDHeader))
+
+		res, err := next(context.WithValue(ctx, requestIDKey{}, id), req)
+		if err != nil {
+			return nil, withRequestID(err, id)
+		}
+
+		res.Header().Set(RequestIDHeader, id)
+
+		return res, nil
+	}
+}
+
+func (r *RequestIDs) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (r *RequestIDs) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		id := requestID(conn.RequestHeader().Get(RequestIDHeader))
+		conn.ResponseHeader().Set(RequestIDHeader, id)
+
+		if err := next(context.WithValue(ctx, requestIDKey{}, id), conn); err != nil {
+			return withRequestID(err, id)
+		}
+
+		return nil
+	}
+}
+
+// requestID returns header when it is a usable ID, or a new one.
+func requestID(header string) string {
+	if header != "" && len(header) <= maxRequestIDLength && printable(header) {
+		return header
+	}
+
+	b := make([]byte, 8)
+	rand.Read(b) //nolint:errcheck
+
+	return hex.EncodeToString(b)
+}
+
+func printable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x21 || s[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// withRequestID adds the request ID to the metadata of err.
+func withRequestID(err error, id string) error {
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		connectErr = connect.NewError(connect.CodeOf(err), err)
+	}
+
+	connectErr.Meta().Set(RequestIDHeader, id)
+
+	return connectErr
+}
@@ -0,0 +1,86 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+)
+
+// Timeouts bounds how long every procedure may run, on top of the deadline
+// the client sent, through the context of its handler. Handlers must stop
+// once it is done; unary RPCs whose handler fails after that fail with
+// DeadlineExceeded, or Canceled when the client went away.
+type Timeouts struct {
+	fallback   time.Duration
+	procedures map[string]time.Duration
+}
+
+// NewTimeouts bounds the procedures, by full procedure name, to their
+// timeouts and every other one to fallback. Timeouts that are not positive
+// leave a procedure unbounded.
+func NewTimeouts(fallback time.Duration, procedures map[string]time.Duration) *Timeouts {
+	return &Timeouts{
+		fallback:   fallback,
+		procedures: procedures,
+	}
+}
+
+// Timeout returns the timeout of procedure, 0 when it is unbounded.
+func (t *Timeouts) Timeout(procedure string) time.Duration {
+	timeout, ok := t.procedures[procedure]
+	if !ok {
+		timeout = t.fallback
+	}
+
+	return max(timeout, 0)
+}
+
+func (t *Timeouts) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		timeout := t.Timeout(req.Spec().Procedure)
+		if timeout == 0 {
+			return next(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		res, err := next(ctx, req)
+		if err != nil && ctx.Err() != nil {
+			// handlers wrap the context error in their own
+			return nil, contextError(ctx.Err())
+		}
+
+		return res, err
+	}
+}
+
+func (t *Timeouts) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (t *Timeouts) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		timeout := t.Timeout(conn.Spec().Procedure)
+		if timeout == 0 {
+			return next(ctx, conn)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return next(ctx, conn)
+	}
+}
+
+// contextError returns the error of a request whose context ended: the
+// deadline passed or the client went away.
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return connect.NewError(connect.CodeDeadlineExceeded, err)
+	}
+
+	return connect.NewError(connect.CodeCanceled, err)
+}
@@ -1,9 +1,13 @@
 package server
 
 import (
+	"encoding/json"
+	"log"
 	"net/http"
 
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/enums"
 	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/handlers"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/interceptors"
 	services "github.com/diegoafg1009/auto-radar-scraping-microservice/internal/services/scraper"
 	"github.com/diegoafg1009/auto-radar-scraping-microservice/pkg/genproto/autoscrapper/v1/autoscrapperv1connect"
 	"golang.org/x/net/http2"
@@ -13,9 +17,118 @@ import (
 func (s *Server) RegisterRoutes() http.Handler {
 	mux := http.NewServeMux()
 
-	path, handler := autoscrapperv1connect.NewAutoScrapperServiceHandler(handlers.NewAutoScrapperHandler(services.NewNeoAutoRodScrapper()))
+	registry := services.NewRegistry()
+
+	// Scraped and feed sources track when their listings leave the market
+	register := func(source enums.ScrapperType, scrapper services.AutoScrapper) {
+		registry.Register(source, s.lifecycle.Track(source.String(), scrapper))
+	}
+
+	register(enums.NeoAuto, services.NewBackendScrapper(
+		s.profiles,
+		enums.NeoAuto.String(),
+		services.NewHTTPScrapper(s.profiles, enums.NeoAuto.String(), s.scrapperOptions...),
+		services.NewNeoAutoRodScrapper(s.profiles, s.scrapperOptions...),
+		s.scrapperOptions...,
+	))
+	register(enums.MercadoLibre, services.NewMercadoLibreScrapper(s.mercadoLibreToken, s.scrapperOptions...))
+
+	registry.Register(enums.Imported, s.imports)
+
+	for _, feed := range s.feeds {
+		source := enums.RegisterScrapperType(feed.Name())
+		if _, ok := registry.Get(source); ok {
+			log.Fatalf("dealer feed %q takes the name of another source", feed.Name())
+		}
+
+		register(source, feed)
+	}
+
+	// Every other site profile is scraped generically under its own name
+	for _, name := range s.profiles.Names() {
+		source := enums.RegisterScrapperType(name)
+		if _, ok := registry.Get(source); ok {
+			continue
+		}
+
+		register(source, services.NewBackendScrapper(
+			s.profiles,
+			name,
+			services.NewHTTPScrapper(s.profiles, name, s.scrapperOptions...),
+			services.NewProfileScrapper(s.profiles, name, s.scrapperOptions...),
+			s.scrapperOptions...,
+		))
+	}
+
+	autoscrapper := s.suggester.Wrap(registry)
+	if s.images != nil {
+		autoscrapper = s.images.Wrap(autoscrapper)
+		mux.Handle("/images/", http.StripPrefix("/images", s.images))
+	}
+
+	// Every service handler runs through the same interceptors
+	chain := interceptors.Chain(s.timeouts)
+
+	path, handler := autoscrapperv1connect.NewAutoScrapperServiceHandler(
+		handlers.NewAutoScrapperHandler(autoscrapper, s.quality, s.runs, s.imports, s.lifecycle, s.catalog, s.suggester),
+		chain,
+	)
 
 	mux.Handle(path, handler)
+	mux.HandleFunc("/health", s.healthHandler(registry))
+	mux.HandleFunc("/metrics", s.metricsHandler)
 
 	return h2c.NewHandler(mux, &http2.Server{})
 }
+
+type sourcesHealth struct {
+	Status  string                           `json:"status"`
+	Sources map[string]services.SourceStatus `json:"sources"`
+}
+
+// healthHandler reports the block state of every scraping source. The status
+// is degraded while any source is cooling down after a block page.
+func (s *Server) healthHandler(registry *services.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		health := sourcesHealth{
+			Status:  "ok",
+			Sources: make(map[string]services.SourceStatus),
+		}
+
+		blocked := s.cooldowns.Status()
+
+		for _, source := range registry.Sources() {
+			status := blocked[source.String()]
+			if status.Blocked {
+				health.Status = "degraded"
+			}
+
+			health.Sources[source.String()] = status
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(health); err != nil {
+			log.Println("Writing health failed:", err)
+		}
+	}
+}
+
+// metricsHandler exposes the scrape quality counters and the proxy health
+// to Prometheus.
+func (s *Server) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	if err := s.quality.WriteMetrics(w); err != nil {
+		log.Println("Writing metrics failed:", err)
+		return
+	}
+
+	if s.proxies == nil {
+		return
+	}
+
+	if err := s.proxies.WriteMetrics(w); err != nil {
+		log.Println("Writing proxy metrics failed:", err)
+	}
+}
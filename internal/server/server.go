@@ -12,20 +12,214 @@ import (
 	_ "github.com/joho/godotenv/autoload"
 
 	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/database"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/enums"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/interceptors"
+	services "github.com/diegoafg1009/auto-radar-scraping-microservice/internal/services/scraper"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/pkg/genproto/autoscrapper/v1/autoscrapperv1connect"
+)
+
+const (
+	defaultProfilesReloadInterval = 30 * time.Second
+	defaultImagesBaseURL          = "/images/"
+	defaultSuggestionsRefresh     = 5 * time.Minute
+
+	defaultRPCTimeout = 10 * time.Second
+	// scrapes answer within the write timeout of the server, with time to
+	// write the response
+	findByFilterTimeout = 25 * time.Second
 )
 
 type Server struct {
 	port      int
 	db        database.Service
+	profiles  *services.ProfileStore
+	cooldowns *services.Cooldowns
+	quality   *services.Quality
+	proxies   *services.ProxyPool
+	runs      services.RunLog
+	feeds     []*services.FeedSource
+	imports   *services.ImportSource
+	lifecycle *services.Lifecycle
+	images    *services.ImageCache
+	catalog   *services.Catalog
+	suggester *services.VehicleSuggester
+	timeouts  *interceptors.Timeouts
 	apiServer *http.Server
+
+	scrapperOptions   []services.Option
+	mercadoLibreToken string
 }
 
 func NewServer() *Server {
 	port, _ := strconv.Atoi(os.Getenv("PORT"))
+
+	// Site profiles are validated at startup so a broken profile never reaches a scrape
+	profiles, err := services.NewProfileStore(os.Getenv("SCRAPER_PROFILES_DIR"))
+	if err != nil {
+		log.Fatalf("site profiles invalid: %v", err)
+	}
+
+	reloadInterval, err := time.ParseDuration(os.Getenv("SCRAPER_PROFILES_RELOAD_INTERVAL"))
+	if err != nil {
+		reloadInterval = defaultProfilesReloadInterval
+	}
+
+	go profiles.Watch(context.Background(), reloadInterval)
+
+	// Record or replay scraper fixtures, e.g. to reproduce a production search locally
+	fixtureMode := os.Getenv("SCRAPER_FIXTURE_MODE")
+	if !services.IsFixtureMode(fixtureMode) {
+		log.Fatalf("unknown scraper fixture mode %q", fixtureMode)
+	}
+
+	// Sources answering with block pages are skipped for an exponentially growing cooldown
+	blockCooldown, _ := time.ParseDuration(os.Getenv("SCRAPER_BLOCK_COOLDOWN"))
+	cooldowns := services.NewCooldowns(blockCooldown)
+
+	// Extraction counts per source, to catch selector drift before users do
+	quality := services.NewQuality()
+
+	// Browser sessions rotate fingerprints, from a YAML file or the built-in desktop ones
+	fingerprints, err := services.LoadFingerprintPool(os.Getenv("SCRAPER_FINGERPRINTS_FILE"))
+	if err != nil {
+		log.Fatalf("scraper fingerprints invalid: %v", err)
+	}
+
+	// Brands and models are resolved in the vehicle catalog, from a YAML file or the built-in one
+	catalog, err := services.LoadCatalog(os.Getenv("SCRAPER_CATALOG_FILE"))
+	if err != nil {
+		log.Fatalf("vehicle catalog invalid: %v", err)
+	}
+
+	db := database.New()
+
+	// Failed and empty browser scrapes can keep their screenshot, HTML and console log
+	artifacts := os.Getenv("SCRAPER_ARTIFACTS")
+	if !services.IsArtifactsKind(artifacts) {
+		log.Fatalf("unknown scraper artifacts store %q", artifacts)
+	}
+
+	// Every scrape run is audited in a capped Redis stream
+	runLogMaxLen, _ := strconv.ParseInt(os.Getenv("SCRAPER_RUN_LOG_MAX_LEN"), 10, 64)
+	runs := services.NewRedisRunLog(db.Client(), runLogMaxLen)
+
+	scrapperOptions := []services.Option{
+		services.WithFixtures(fixtureMode, os.Getenv("SCRAPER_FIXTURE_DIR")),
+		services.WithPoliteness(services.NewPoliteness()),
+		services.WithCooldowns(cooldowns),
+		services.WithFingerprints(fingerprints),
+		services.WithQuality(quality),
+		services.WithRunLog(runs),
+		services.WithCatalog(catalog),
+	}
+
+	switch artifacts {
+	case services.ArtifactsDir:
+		scrapperOptions = append(scrapperOptions, services.WithArtifacts(services.NewDirArtifactStore(os.Getenv("SCRAPER_ARTIFACTS_DIR"))))
+	case services.ArtifactsRedis:
+		ttl, _ := time.ParseDuration(os.Getenv("SCRAPER_ARTIFACTS_TTL"))
+		scrapperOptions = append(scrapperOptions, services.WithArtifacts(services.NewRedisArtifactStore(db.Client(), ttl)))
+	}
+
+	// Outbound proxies are optional; without them scrapers connect directly
+	var proxyPool *services.ProxyPool
+	if proxies := services.ParseProxyList(os.Getenv("SCRAPER_PROXIES")); len(proxies) > 0 {
+		bench, _ := time.ParseDuration(os.Getenv("SCRAPER_PROXY_BENCH"))
+
+		pool, err := services.NewProxyPool(proxies, os.Getenv("SCRAPER_PROXY_ROTATION"), bench)
+		if err != nil {
+			log.Fatalf("scraper proxies invalid: %v", err)
+		}
+
+		proxyPool = pool
+		scrapperOptions = append(scrapperOptions, services.WithProxyPool(pool))
+	}
+
+	// Dealer inventory feeds are sources of their own, ingested on a schedule;
+	// their names may not shadow the built-in sources nor the site profiles
+	reserved := profiles.Names()
+	for _, name := range enums.ScrapperTypeNames {
+		reserved = append(reserved, name)
+	}
+
+	feedSpecs, err := services.LoadFeeds(os.Getenv("SCRAPER_FEEDS_FILE"), reserved)
+	if err != nil {
+		log.Fatalf("dealer feeds invalid: %v", err)
+	}
+
+	feeds := make([]*services.FeedSource, 0, len(feedSpecs))
+	for _, spec := range feedSpecs {
+		feed := services.NewFeedSource(spec, scrapperOptions...)
+		go feed.Watch(context.Background())
+
+		feeds = append(feeds, feed)
+	}
+
+	// Listings pushed by other tools through ImportListings, kept in Redis by URL;
+	// they expire unless imported again and are capped in number
+	importsTTL, _ := time.ParseDuration(os.Getenv("SCRAPER_IMPORTS_TTL"))
+	importsMax, _ := strconv.ParseInt(os.Getenv("SCRAPER_IMPORTS_MAX"), 10, 64)
+	imports := services.NewImportSource(enums.Imported.String(), services.NewRedisImportStore(db.Client(), importsTTL, importsMax), scrapperOptions...)
+
+	// Listings missing from repeated scrapes of a filter are probed to tell when they left the market
+	lifecycleMisses, _ := strconv.Atoi(os.Getenv("SCRAPER_LIFECYCLE_MISSES"))
+	lifecycle := services.NewLifecycle(services.NewRedisLifecycleStore(db.Client()), lifecycleMisses, scrapperOptions...)
+	go lifecycle.Run(context.Background())
+
+	// Listing images are optionally cached on disk and served from /images/, out of reach of hotlink blocks
+	var images *services.ImageCache
+	if dir := os.Getenv("SCRAPER_IMAGES_DIR"); dir != "" {
+		baseURL := os.Getenv("SCRAPER_IMAGES_BASE_URL")
+		if baseURL == "" {
+			baseURL = defaultImagesBaseURL
+		}
+
+		images = services.NewImageCache(services.NewDirBlobStore(dir), baseURL, scrapperOptions...)
+		go images.Run(context.Background())
+	}
+
+	// Brand and model suggestions rank by the listings scraped lately, refreshed from Redis in the background
+	suggestionsWindow, _ := time.ParseDuration(os.Getenv("SCRAPER_SUGGESTIONS_WINDOW"))
+	suggester := services.NewVehicleSuggester(catalog, services.NewRedisSeenStore(db.Client()), suggestionsWindow)
+
+	suggestionsRefresh, err := time.ParseDuration(os.Getenv("SCRAPER_SUGGESTIONS_REFRESH"))
+	if err != nil || suggestionsRefresh <= 0 {
+		suggestionsRefresh = defaultSuggestionsRefresh
+	}
+
+	go suggester.Watch(context.Background(), suggestionsRefresh)
+
+	// Every RPC is bounded by its procedure timeout, ImportListings only by the write timeout
+	rpcTimeout, err := time.ParseDuration(os.Getenv("RPC_TIMEOUT"))
+	if err != nil || rpcTimeout <= 0 {
+		rpcTimeout = defaultRPCTimeout
+	}
+
+	timeouts := interceptors.NewTimeouts(rpcTimeout, map[string]time.Duration{
+		autoscrapperv1connect.AutoScrapperServiceFindByFilterProcedure:   findByFilterTimeout,
+		autoscrapperv1connect.AutoScrapperServiceImportListingsProcedure: 0,
+	})
+
 	NewServer := &Server{
 		port: port,
 
-		db: database.New(),
+		db:              db,
+		profiles:        profiles,
+		cooldowns:       cooldowns,
+		quality:         quality,
+		proxies:         proxyPool,
+		runs:            runs,
+		feeds:           feeds,
+		imports:         imports,
+		lifecycle:       lifecycle,
+		images:          images,
+		catalog:         catalog,
+		suggester:       suggester,
+		timeouts:        timeouts,
+		scrapperOptions: scrapperOptions,
+
+		// Optional, the public search works without it at lower rate limits
+		mercadoLibreToken: os.Getenv("MERCADOLIBRE_ACCESS_TOKEN"),
 	}
 
 	// Declare Server config
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/redis/go-redis/v9"
+)
+
+// Artifact stores. Failed and empty browser scrapes save what the page looked
+// like under a scrape ID, so failures can be debugged without reproducing
+// them live.
+const (
+	ArtifactsOff   = ""
+	ArtifactsDir   = "dir"
+	ArtifactsRedis = "redis"
+)
+
+const (
+	artifactsSaveTimeout = 10 * time.Second
+	defaultArtifactsTTL  = 72 * time.Hour
+)
+
+// Artifacts are the state of a browser page when its scrape failed or found
+// no results.
+type Artifacts struct {
+	ScrapeID   string    `json:"scrape_id"`
+	Source     string    `json:"source"`
+	URL        string    `json:"url"`
+	Error      string    `json:"error,omitempty"`
+	CapturedAt time.Time `json:"captured_at"`
+	Screenshot []byte    `json:"-"`
+	HTML       string    `json:"-"`
+	Console    []string  `json:"-"`
+}
+
+// EmptyScrape is a browser scrape that found no results, whose artifacts
+// were saved under ScrapeID.
+type EmptyScrape struct {
+	Source   string
+	ScrapeID string
+}
+
+type emptyScrapesKey struct{}
+
+// emptyScrapes collects the empty scrapes of a search.
+type emptyScrapes struct {
+	mu      sync.Mutex
+	scrapes []EmptyScrape
+}
+
+// WithEmptyScrapes returns a context collecting the empty scrapes of the
+// searches run with it, and the function returning those collected so far.
+// Failed scrapes return their scrape ID in their error instead.
+func WithEmptyScrapes(ctx context.Context) (context.Context, func() []EmptyScrape) {
+	collected := &emptyScrapes{}
+
+	return context.WithValue(ctx, emptyScrapesKey{}, collected), func() []EmptyScrape {
+		collected.mu.Lock()
+		defer collected.mu.Unlock()
+
+		return slices.Clone(collected.scrapes)
+	}
+}
+
+// reportEmptyScrape adds scrape to the empty scrapes collected by ctx, if any.
+func reportEmptyScrape(ctx context.Context, scrape EmptyScrape) {
+	collected, ok := ctx.Value(emptyScrapesKey{}).(*emptyScrapes)
+	if !ok {
+		return
+	}
+
+	collected.mu.Lock()
+	defer collected.mu.Unlock()
+
+	collected.scrapes = append(collected.scrapes, scrape)
+}
+
+// ArtifactStore keeps the artifacts of failed scrapes.
+type ArtifactStore interface {
+	Save(ctx context.Context, artifacts *Artifacts) error
+}
+
+// IsArtifactsKind reports whether kind is a known artifact store.
+func IsArtifactsKind(kind string) bool {
+	switch kind {
+	case ArtifactsOff, ArtifactsDir, ArtifactsRedis:
+		return true
+	}
+
+	return false
+}
+
+// DirArtifactStore writes the artifacts of each scrape to a directory named
+// after its scrape ID.
+type DirArtifactStore struct {
+	dir string
+}
+
+func NewDirArtifactStore(dir string) *DirArtifactStore {
+	return &DirArtifactStore{dir: dir}
+}
+
+func (s *DirArtifactStore) Save(ctx context.Context, artifacts *Artifacts) error {
+	dir := filepath.Join(s.dir, artifacts.ScrapeID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	meta, err := json.MarshalIndent(artifacts, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		"meta.json":   meta,
+		"page.html":   []byte(artifacts.HTML),
+		"console.log": []byte(strings.Join(artifacts.Console, "\n")),
+	}
+
+	if len(artifacts.Screenshot) > 0 {
+		files["screenshot.png"] = artifacts.Screenshot
+	}
+
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RedisArtifactStore keeps the artifacts of each scrape in a Redis hash at
+// scrape:artifacts:<scrape ID>, expiring after ttl.
+type RedisArtifactStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisArtifactStore keeps the artifacts for ttl, 72h when it is not
+// positive.
+func NewRedisArtifactStore(client *redis.Client, ttl time.Duration) *RedisArtifactStore {
+	if ttl <= 0 {
+		ttl = defaultArtifactsTTL
+	}
+
+	return &RedisArtifactStore{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+func (s *RedisArtifactStore) Save(ctx context.Context, artifacts *Artifacts) error {
+	meta, err := json.Marshal(artifacts)
+	if err != nil {
+		return err
+	}
+
+	key := "scrape:artifacts:" + artifacts.ScrapeID
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			"meta", meta,
+			"html", artifacts.HTML,
+			"console", strings.Join(artifacts.Console, "\n"),
+			"screenshot", artifacts.Screenshot,
+		)
+		pipe.Expire(ctx, key, s.ttl)
+
+		return nil
+	})
+
+	return err
+}
+
+// WithArtifacts saves the artifacts of failed and empty browser scrapes to
+// store.
+func WithArtifacts(store ArtifactStore) Option {
+	return func(o *scrapperOptions) {
+		o.artifacts = store
+	}
+}
+
+// ScrapeError is a scrape failure with the ID of its run, which its artifacts
+// are saved under when they are kept.
+type ScrapeError struct {
+	ScrapeID string
+	Err      error
+}
+
+func (e *ScrapeError) Error() string {
+	return fmt.Sprintf("%v (scrape %s)", e.Err, e.ScrapeID)
+}
+
+func (e *ScrapeError) Unwrap() error {
+	return e.Err
+}
+
+// ScrapeID returns the ID of the run of a failed scrape, if any.
+func ScrapeID(err error) (string, bool) {
+	var scrapeErr *ScrapeError
+	if !errors.As(err, &scrapeErr) {
+		return "", false
+	}
+
+	return scrapeErr.ScrapeID, true
+}
+
+// scrapeError returns err as a ScrapeError of scrapeID, unless it is nil or
+// one already.
+func scrapeError(scrapeID string, err error) error {
+	var scrapeErr *ScrapeError
+	if err == nil || errors.As(err, &scrapeErr) {
+		return err
+	}
+
+	return &ScrapeError{ScrapeID: scrapeID, Err: err}
+}
+
+// newScrapeID returns a random identifier for a scrape.
+func newScrapeID() string {
+	b := make([]byte, 8)
+	rand.Read(b) //nolint:errcheck
+
+	return hex.EncodeToString(b)
+}
+
+// consoleLog collects the console messages and uncaught exceptions of a page.
+type consoleLog struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (c *consoleLog) add(line string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lines = append(c.lines, line)
+}
+
+func (c *consoleLog) Lines() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]string(nil), c.lines...)
+}
+
+// captureConsole starts collecting the console of page when artifacts are
+// saved. It must run before the page navigates; the returned function stops
+// it.
+func (o scrapperOptions) captureConsole(page *rod.Page) (*consoleLog, func()) {
+	console := &consoleLog{}
+
+	if o.artifacts == nil {
+		return console, func() {}
+	}
+
+	if err := (proto.RuntimeEnable{}).Call(page); err != nil {
+		return console, func() {}
+	}
+
+	events, cancel := page.WithCancel()
+
+	go events.EachEvent(func(e *proto.RuntimeConsoleAPICalled) {
+		args := make([]string, 0, len(e.Args))
+		for _, arg := range e.Args {
+			if arg.Value.Nil() {
+				args = append(args, arg.Description)
+				continue
+			}
+
+			args = append(args, arg.Value.String())
+		}
+
+		console.add(fmt.Sprintf("%s %s: %s", consoleTime(e.Timestamp), e.Type, strings.Join(args, " ")))
+	}, func(e *proto.RuntimeExceptionThrown) {
+		console.add(fmt.Sprintf("%s exception: %s", consoleTime(e.Timestamp), e.ExceptionDetails.Text))
+	})()
+
+	return console, cancel
+}
+
+// saveArtifacts saves the page of a run that failed with err or found no
+// results, under the run ID, and records it on the run. Failures come back as
+// a ScrapeError with the scrape ID, empty scrapes are reported to the
+// collector of ctx; saving is best effort and never hides err.
+func (o scrapperOptions) saveArtifacts(ctx context.Context, page *rod.Page, console *consoleLog, run *scrapeRun, found int, err error) error {
+	if o.artifacts == nil || (err == nil && found > 0) {
+		return err
+	}
+
+	artifacts := &Artifacts{
+		ScrapeID:   run.ID,
+		Source:     run.Source,
+		URL:        run.URL,
+		CapturedAt: time.Now(),
+		Console:    console.Lines(),
+	}
+
+	if err != nil {
+		artifacts.Error = err.Error()
+	}
+
+	if info, infoErr := page.Info(); infoErr == nil {
+		artifacts.URL = info.URL
+	}
+
+	if screenshot, shotErr := page.Screenshot(true, nil); shotErr == nil {
+		artifacts.Screenshot = screenshot
+	}
+
+	if html, htmlErr := page.HTML(); htmlErr == nil {
+		artifacts.HTML = html
+	}
+
+	// saved even when the search was canceled
+	saveCtx, cancel := context.WithTimeout(context.Background(), artifactsSaveTimeout)
+	defer cancel()
+
+	if saveErr := o.artifacts.Save(saveCtx, artifacts); saveErr != nil {
+		log.Println(run.Source, "saving scrape artifacts failed:", saveErr)
+		return err
+	}
+
+	run.ArtifactsID = artifacts.ScrapeID
+
+	if err == nil {
+		log.Println(run.Source, "found no results, artifacts saved as scrape", artifacts.ScrapeID)
+		reportEmptyScrape(ctx, EmptyScrape{Source: run.Source, ScrapeID: artifacts.ScrapeID})
+
+		return nil
+	}
+
+	log.Println(run.Source, "scrape failed, artifacts saved as scrape", artifacts.ScrapeID)
+
+	return &ScrapeError{ScrapeID: artifacts.ScrapeID, Err: err}
+}
+
+// consoleTime formats a console timestamp, in milliseconds since the epoch.
+func consoleTime(timestamp proto.RuntimeTimestamp) string {
+	return time.UnixMilli(int64(timestamp)).Format(time.RFC3339)
+}
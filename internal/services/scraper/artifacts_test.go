@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirArtifactStoreSave(t *testing.T) {
+	dir := t.TempDir()
+
+	artifacts := &Artifacts{
+		ScrapeID:   newScrapeID(),
+		Source:     "Test",
+		URL:        "https://autos.test/usados",
+		Error:      ErrResultsNotFound.Error(),
+		Screenshot: []byte("png"),
+		HTML:       "<html></html>",
+		Console:    []string{"error: boom", "log: done"},
+	}
+
+	if err := NewDirArtifactStore(dir).Save(context.Background(), artifacts); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	want := map[string]string{
+		"page.html":      "<html></html>",
+		"console.log":    "error: boom\nlog: done",
+		"screenshot.png": "png",
+	}
+
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(dir, artifacts.ScrapeID, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", name, got, content)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, artifacts.ScrapeID, "meta.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var meta Artifacts
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.ScrapeID != artifacts.ScrapeID || meta.URL != artifacts.URL || meta.Error != artifacts.Error {
+		t.Errorf("meta.json = %+v, want the scrape ID, URL and error of %+v", meta, artifacts)
+	}
+}
+
+func TestScrapeID(t *testing.T) {
+	err := fmt.Errorf("NeoAuto: %w", &ScrapeError{ScrapeID: "abc123", Err: ErrResultsNotFound})
+
+	if id, ok := ScrapeID(err); !ok || id != "abc123" {
+		t.Errorf("ScrapeID() = %q, %v, want abc123, true", id, ok)
+	}
+
+	if _, ok := ScrapeID(ErrResultsNotFound); ok {
+		t.Error("ScrapeID() of an error without artifacts = true, want false")
+	}
+}
+
+func TestWithEmptyScrapes(t *testing.T) {
+	// without a collector, reports are dropped
+	reportEmptyScrape(context.Background(), EmptyScrape{Source: "NeoAuto", ScrapeID: "dropped"})
+
+	ctx, emptyScrapes := WithEmptyScrapes(context.Background())
+
+	reportEmptyScrape(ctx, EmptyScrape{Source: "NeoAuto", ScrapeID: "abc"})
+	reportEmptyScrape(ctx, EmptyScrape{Source: "autocosmos", ScrapeID: "def"})
+
+	got := emptyScrapes()
+	if len(got) != 2 || got[0].ScrapeID != "abc" || got[1].Source != "autocosmos" {
+		t.Errorf("emptyScrapes() = %+v, want both reported scrapes", got)
+	}
+}
@@ -1,9 +1,12 @@
 package services
 
 import (
+	"context"
+
 	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
 )
 
 type AutoScrapper interface {
-	FindByFilter(filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error)
+	// FindByFilter gives up with the context error once ctx is done.
+	FindByFilter(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error)
 }
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+)
+
+// BackendScrapper picks the backend of a source from its site profile on
+// every search. With the http backend the static HTML is scraped first and
+// the rod backend is used when it fails or has no results. Sources answering
+// with block pages are skipped until their cooldown ends.
+type BackendScrapper struct {
+	profiles *ProfileStore
+	name     string
+	static   AutoScrapper
+	browser  AutoScrapper
+	options  scrapperOptions
+}
+
+func NewBackendScrapper(profiles *ProfileStore, name string, static AutoScrapper, browser AutoScrapper, opts ...Option) *BackendScrapper {
+	return &BackendScrapper{
+		profiles: profiles,
+		name:     name,
+		static:   static,
+		browser:  browser,
+		options:  newScrapperOptions(opts),
+	}
+}
+
+func (s *BackendScrapper) FindByFilter(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+	profile, err := s.profiles.Profile(s.name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.options.checkCooldown(profile.Name); err != nil {
+		return nil, err
+	}
+
+	autos, err := s.find(ctx, profile, filter)
+
+	return autos, s.options.updateCooldown(profile.Name, err)
+}
+
+func (s *BackendScrapper) find(ctx context.Context, profile *SiteProfile, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+	if profile.Backend != BackendHTTP {
+		return s.browser.FindByFilter(ctx, filter)
+	}
+
+	autos, err := s.static.FindByFilter(ctx, filter)
+	if err == nil && len(autos) > 0 {
+		return autos, nil
+	}
+
+	// retrying a blocking site from a browser right away only extends the block
+	if errors.Is(err, ErrBlocked) {
+		return nil, err
+	}
+
+	if err != nil {
+		log.Println(profile.Name, "static HTML scrape failed, falling back to rod:", err)
+	} else {
+		log.Println(profile.Name, "static HTML has no results, falling back to rod")
+	}
+
+	return s.browser.FindByFilter(ctx, filter)
+}
@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Kinds of block pages.
+const (
+	BlockChallenge    = "challenge"
+	BlockCaptcha      = "captcha"
+	BlockAccessDenied = "access_denied"
+	BlockRateLimited  = "rate_limited"
+)
+
+const (
+	defaultBlockCooldown = time.Minute
+	maxBlockCooldown     = time.Hour
+)
+
+var (
+	// ErrBlocked is matched by every error caused by a source blocking us.
+	ErrBlocked = errors.New("blocked by source")
+
+	// ErrResultsNotFound is returned when a page has neither results nor a
+	// known block page, usually because the site markup changed.
+	ErrResultsNotFound = errors.New("results not found")
+)
+
+// BlockedError reports a block page, or a source still cooling down after
+// one. RetryAfter is how long until the source is tried again.
+type BlockedError struct {
+	Source     string
+	Kind       string
+	RetryAfter time.Duration
+}
+
+func (e *BlockedError) Error() string {
+	if e.RetryAfter <= 0 {
+		return fmt.Sprintf("%s: %s (%s)", e.Source, ErrBlocked, e.Kind)
+	}
+
+	return fmt.Sprintf("%s: %s (%s), retry after %s", e.Source, ErrBlocked, e.Kind, e.RetryAfter.Round(time.Second))
+}
+
+func (e *BlockedError) Is(target error) bool {
+	return target == ErrBlocked
+}
+
+// BlockSignature recognizes a block page of the given Kind by a case
+// insensitive regular expression matched against its HTML.
+type BlockSignature struct {
+	Kind    string `yaml:"kind"`
+	Pattern string `yaml:"pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// defaultBlockSignatures are checked for every source, before the
+// signatures of its profile.
+var defaultBlockSignatures = []BlockSignature{
+	{Kind: BlockChallenge, Pattern: `cf-chl-|challenge-platform|cf-browser-verification|<title>just a moment\.\.\.</title>|checking your browser before accessing`},
+	{Kind: BlockCaptcha, Pattern: `class="[^"]*(g-recaptcha|h-captcha)|captcha-delivery\.com|px-captcha|<title>[^<]*captcha`},
+	{Kind: BlockAccessDenied, Pattern: `<title>access denied</title>|attention required! \| cloudflare|you have been blocked|acceso denegado|request unsuccessful\. incapsula`},
+}
+
+func init() {
+	for i := range defaultBlockSignatures {
+		if err := defaultBlockSignatures[i].compile(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (s *BlockSignature) compile() error {
+	switch s.Kind {
+	case BlockChallenge, BlockCaptcha, BlockAccessDenied, BlockRateLimited:
+	default:
+		return fmt.Errorf("unknown block kind %q", s.Kind)
+	}
+
+	pattern, err := regexp.Compile("(?i)" + s.Pattern)
+	if err != nil {
+		return err
+	}
+
+	s.pattern = pattern
+
+	return nil
+}
+
+// DetectBlock returns the kind of block page a response with the given
+// status and HTML is. It should only be used on pages without results, since
+// regular pages may embed captcha widgets in their forms.
+func (p *SiteProfile) DetectBlock(status int, html string) (string, bool) {
+	for _, signatures := range [][]BlockSignature{defaultBlockSignatures, p.BlockSignatures} {
+		for _, signature := range signatures {
+			if signature.pattern.MatchString(html) {
+				return signature.Kind, true
+			}
+		}
+	}
+
+	switch status {
+	case http.StatusTooManyRequests:
+		return BlockRateLimited, true
+	case http.StatusForbidden:
+		return BlockAccessDenied, true
+	}
+
+	return "", false
+}
+
+// blocked returns a BlockedError when the response is a block page of the
+// site, nil otherwise.
+func (p *SiteProfile) blocked(status int, html string) error {
+	kind, ok := p.DetectBlock(status, html)
+	if !ok {
+		return nil
+	}
+
+	return &BlockedError{Source: p.Name, Kind: kind}
+}
+
+// WithCooldowns keeps sources blocking the scrapper aside through cooldowns,
+// which should be shared by all the scrappers.
+func WithCooldowns(cooldowns *Cooldowns) Option {
+	return func(o *scrapperOptions) {
+		o.cooldowns = cooldowns
+	}
+}
+
+// SourceStatus is the block state of a source.
+type SourceStatus struct {
+	Blocked      bool      `json:"blocked"`
+	Kind         string    `json:"kind,omitempty"`
+	Strikes      int       `json:"strikes,omitempty"`
+	BlockedSince time.Time `json:"blocked_since,omitempty"`
+	BlockedUntil time.Time `json:"blocked_until,omitempty"`
+}
+
+// Cooldowns keeps blocked sources aside for an exponentially growing time:
+// base after the first block, doubling on every block in a row, up to max.
+type Cooldowns struct {
+	base time.Duration
+	max  time.Duration
+
+	mu      sync.Mutex
+	sources map[string]*SourceStatus
+}
+
+func NewCooldowns(base time.Duration) *Cooldowns {
+	if base <= 0 {
+		base = defaultBlockCooldown
+	}
+
+	return &Cooldowns{
+		base:    base,
+		max:     max(base, maxBlockCooldown),
+		sources: make(map[string]*SourceStatus),
+	}
+}
+
+// Check returns a BlockedError while source is cooling down.
+func (c *Cooldowns) Check(source string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status, ok := c.sources[source]
+	if !ok {
+		return nil
+	}
+
+	retryAfter := time.Until(status.BlockedUntil)
+	if retryAfter <= 0 {
+		return nil
+	}
+
+	return &BlockedError{Source: source, Kind: status.Kind, RetryAfter: retryAfter}
+}
+
+// Block starts or extends the cooldown of source and returns the matching
+// BlockedError.
+func (c *Cooldowns) Block(source string, kind string) *BlockedError {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status, ok := c.sources[source]
+	if !ok {
+		status = &SourceStatus{}
+		c.sources[source] = status
+	}
+
+	now := time.Now()
+
+	if !status.Blocked {
+		status.BlockedSince = now
+	}
+
+	cooldown := c.base << status.Strikes
+	if cooldown > c.max || cooldown <= 0 {
+		cooldown = c.max
+	}
+
+	status.Blocked = true
+	status.Kind = kind
+	status.Strikes++
+	status.BlockedUntil = now.Add(cooldown)
+
+	return &BlockedError{Source: source, Kind: kind, RetryAfter: cooldown}
+}
+
+// Clear resets source after a successful scrape.
+func (c *Cooldowns) Clear(source string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.sources, source)
+}
+
+// Status returns the block state of every source blocked since its last
+// successful scrape.
+func (c *Cooldowns) Status() map[string]SourceStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	statuses := make(map[string]SourceStatus, len(c.sources))
+
+	for source, status := range c.sources {
+		s := *status
+		s.Blocked = now.Before(status.BlockedUntil)
+		statuses[source] = s
+	}
+
+	return statuses
+}
+
+// checkCooldown returns a BlockedError while source is cooling down.
+func (o scrapperOptions) checkCooldown(source string) error {
+	if o.cooldowns == nil {
+		return nil
+	}
+
+	return o.cooldowns.Check(source)
+}
+
+// updateCooldown starts the cooldown of source when err is a block, and
+// clears it after a success. It returns err, with the retry delay when the
+// source was blocked.
+func (o scrapperOptions) updateCooldown(source string, err error) error {
+	if o.cooldowns == nil {
+		return err
+	}
+
+	var blockedErr *BlockedError
+
+	switch {
+	case err == nil:
+		o.cooldowns.Clear(source)
+	case errors.As(err, &blockedErr):
+		log.Println(source, "is blocking us:", blockedErr.Kind)
+		return o.cooldowns.Block(source, blockedErr.Kind)
+	}
+
+	return err
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/enums"
+)
+
+const challengePage = `<!DOCTYPE html><html><head><title>Just a moment...</title></head>
+<body><div id="challenge-form" class="cf-browser-verification"></div></body></html>`
+
+func TestDetectBlock(t *testing.T) {
+	profile := &SiteProfile{
+		Name:            "Test",
+		BlockSignatures: []BlockSignature{{Kind: BlockAccessDenied, Pattern: `tu ip ha sido bloqueada`}},
+	}
+	if err := profile.BlockSignatures[0].compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		html   string
+		want   string
+	}{
+		{name: "challenge", status: http.StatusServiceUnavailable, html: challengePage, want: BlockChallenge},
+		{name: "captcha", status: http.StatusOK, html: `<form><div class="g-recaptcha" data-sitekey="x"></div></form>`, want: BlockCaptcha},
+		{name: "profile signature", status: http.StatusOK, html: `<p>Tu IP ha sido bloqueada</p>`, want: BlockAccessDenied},
+		{name: "rate limited", status: http.StatusTooManyRequests, html: `slow down`, want: BlockRateLimited},
+		{name: "results", status: http.StatusOK, html: `<div class="results"><article></article></div>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := profile.DetectBlock(tt.status, tt.html)
+			if got != tt.want {
+				t.Errorf("DetectBlock() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCooldownsBackOffExponentially(t *testing.T) {
+	cooldowns := NewCooldowns(time.Minute)
+
+	if got := cooldowns.Block("Test", BlockCaptcha).RetryAfter; got != time.Minute {
+		t.Errorf("first RetryAfter = %s, want 1m", got)
+	}
+
+	if got := cooldowns.Block("Test", BlockCaptcha).RetryAfter; got != 2*time.Minute {
+		t.Errorf("second RetryAfter = %s, want 2m", got)
+	}
+
+	if err := cooldowns.Check("Test"); !errors.Is(err, ErrBlocked) {
+		t.Errorf("Check() error = %v, want %v", err, ErrBlocked)
+	}
+
+	cooldowns.Clear("Test")
+
+	if err := cooldowns.Check("Test"); err != nil {
+		t.Errorf("Check() after Clear error = %v", err)
+	}
+}
+
+func TestBackendScrapperCoolsDownBlockedSource(t *testing.T) {
+	var hits atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(challengePage))
+	}))
+	t.Cleanup(srv.Close)
+
+	profiles := newTestProfileStore(t)
+	cooldowns := NewCooldowns(time.Minute)
+	static := NewHTTPScrapper(profiles, enums.NeoAuto.String(), WithBaseURL(srv.URL+"/"))
+
+	// the NeoAuto profile uses the rod backend, stood in by the static scrapper
+	s := NewBackendScrapper(profiles, enums.NeoAuto.String(), static, static, WithCooldowns(cooldowns))
+
+	_, err := s.FindByFilter(context.Background(), dtos.AutoFilter{})
+
+	var blockedErr *BlockedError
+	if !errors.As(err, &blockedErr) || blockedErr.Kind != BlockChallenge || blockedErr.RetryAfter != time.Minute {
+		t.Fatalf("FindByFilter() error = %v, want a challenge block with a 1m cooldown", err)
+	}
+
+	if _, err := s.FindByFilter(context.Background(), dtos.AutoFilter{}); !errors.Is(err, ErrBlocked) {
+		t.Fatalf("FindByFilter() during cooldown error = %v, want %v", err, ErrBlocked)
+	}
+
+	if got := hits.Load(); got != 1 {
+		t.Errorf("source hit %d times, want 1", got)
+	}
+
+	if status := cooldowns.Status()[enums.NeoAuto.String()]; !status.Blocked || status.Kind != BlockChallenge {
+		t.Errorf("Status() = %+v, want blocked by a challenge", status)
+	}
+}
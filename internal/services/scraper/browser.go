@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// ErrBrowserUnavailable is returned when no headless browser can be started.
+var ErrBrowserUnavailable = errors.New("browser unavailable")
+
+// launchBrowser starts a headless Chromium and connects rod to it, giving up
+// when ctx is done before it starts. All the browser traffic goes through
+// proxy when it is not nil.
+func launchBrowser(ctx context.Context, proxy *Proxy, fingerprint Fingerprint) (*rod.Browser, error) {
+	path, hasLauncher := launcher.LookPath()
+	if !hasLauncher {
+		return nil, fmt.Errorf("%w: launcher not found", ErrBrowserUnavailable)
+	}
+
+	l := launcher.New().Context(ctx).Headless(true).Leakless(true).Bin(path).
+		Set("disable-blink-features", "AutomationControlled").
+		Set("lang", fingerprint.Locale).
+		Set("window-size", fmt.Sprintf("%d,%d", fingerprint.Width, fingerprint.Height))
+
+	if proxy != nil {
+		// Chromium takes the proxy credentials through an auth challenge
+		l = l.Proxy(proxy.URL.Scheme + "://" + proxy.URL.Host)
+	}
+
+	u, err := l.Launch()
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, fmt.Errorf("%w: %v", ErrBrowserUnavailable, err)
+	}
+
+	browser := rod.New().ControlURL(u)
+	if err := browser.Connect(); err != nil {
+		// the browser started, only rod failed to reach it
+		l.Kill()
+		return nil, fmt.Errorf("%w: %v", ErrBrowserUnavailable, err)
+	}
+
+	if proxy != nil && proxy.URL.User != nil {
+		if err := handleProxyAuth(browser, proxy); err != nil {
+			browser.Close() //nolint:errcheck
+			return nil, fmt.Errorf("%w: proxy auth: %v", ErrBrowserUnavailable, err)
+		}
+	}
+
+	return browser, nil
+}
+
+// handleProxyAuth answers every proxy auth challenge of browser with the
+// credentials of proxy, for the whole browser session. Handling auth requests
+// pauses every request, so paused requests are let through as they come.
+// Calls run off the event loop, which must keep delivering.
+func handleProxyAuth(browser *rod.Browser, proxy *Proxy) error {
+	username := proxy.URL.User.Username()
+	password, _ := proxy.URL.User.Password()
+
+	if err := (proto.FetchEnable{HandleAuthRequests: true}).Call(browser); err != nil {
+		return err
+	}
+
+	go browser.EachEvent(func(e *proto.FetchRequestPaused) {
+		go proto.FetchContinueRequest{RequestID: e.RequestID}.Call(browser) //nolint:errcheck
+	}, func(e *proto.FetchAuthRequired) {
+		go proto.FetchContinueWithAuth{
+			RequestID: e.RequestID,
+			AuthChallengeResponse: &proto.FetchAuthChallengeResponse{
+				Response: proto.FetchAuthChallengeResponseResponseProvideCredentials,
+				Username: username,
+				Password: password,
+			},
+		}.Call(browser) //nolint:errcheck
+	})()
+
+	return nil
+}
+
+// newPage opens a page presenting fingerprint.
+func newPage(browser *rod.Browser, fingerprint Fingerprint) (*rod.Page, error) {
+	page, err := browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fingerprint.apply(page); err != nil {
+		page.Close() //nolint:errcheck
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// missingResults explains why the results of profile never showed up on page:
+// a BlockedError when it is a block page, ErrResultsNotFound otherwise. A
+// page whose context is done returns the context error instead.
+func missingResults(page *rod.Page, profile *SiteProfile, cause error) error {
+	if err := page.GetContext().Err(); err != nil {
+		return err
+	}
+
+	if html, err := page.HTML(); err == nil {
+		if err := profile.blocked(0, html); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("%w: %s: %v", ErrResultsNotFound, profile.ListContainer, cause)
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"golang.org/x/text/unicode/norm"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed catalog/vehicles.yaml
+var defaultCatalog []byte
+
+// maxNameWords is the most words of a brand or model name.
+const maxNameWords = 3
+
+// ErrUnknownBrand is returned for brands the catalog cannot resolve.
+var ErrUnknownBrand = errors.New("unknown brand")
+
+// VehicleModel is a model of a brand, with the aliases clients may send for
+// it and its slugs by source.
+type VehicleModel struct {
+	Name    string            `yaml:"name"`
+	Aliases []string          `yaml:"aliases"`
+	Slugs   map[string]string `yaml:"slugs"`
+}
+
+// Slug returns the slug of the model in the search URLs of source.
+func (m *VehicleModel) Slug(source string) string {
+	if value, ok := m.Slugs[source]; ok {
+		return value
+	}
+
+	return slug(m.Name)
+}
+
+// VehicleBrand is a brand with its models, the aliases clients may send for
+// it and its slugs by source.
+type VehicleBrand struct {
+	Name    string            `yaml:"name"`
+	Aliases []string          `yaml:"aliases"`
+	Slugs   map[string]string `yaml:"slugs"`
+	Models  []*VehicleModel   `yaml:"models"`
+
+	models map[string]*VehicleModel
+}
+
+// Slug returns the slug of the brand in the search URLs of source.
+func (b *VehicleBrand) Slug(source string) string {
+	if value, ok := b.Slugs[source]; ok {
+		return value
+	}
+
+	return slug(b.Name)
+}
+
+// VehicleMatch is what a brand and model resolved to. Model is nil when only
+// the brand resolved. Score is 1 for names and aliases, lower for typos.
+type VehicleMatch struct {
+	Brand *VehicleBrand
+	Model *VehicleModel
+	Score float64
+}
+
+// Catalog holds the brands and models the sources are searched for. Names
+// and aliases are matched ignoring case, accents, spaces and punctuation, so
+// "VW" and "volks wagen" are Volkswagen and "mercedes benz" is Mercedes-Benz.
+type Catalog struct {
+	Brands []*VehicleBrand `yaml:"brands"`
+
+	brands map[string]*VehicleBrand
+}
+
+// LoadCatalog reads the catalog at path, or the embedded one when path is
+// empty.
+func LoadCatalog(path string) (*Catalog, error) {
+	data := defaultCatalog
+
+	if path != "" {
+		var err error
+
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	catalog, err := ParseCatalog(data)
+	if err != nil && path != "" {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return catalog, err
+}
+
+// ParseCatalog decodes a YAML catalog and indexes its names and aliases.
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var catalog Catalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, err
+	}
+
+	if err := catalog.index(); err != nil {
+		return nil, err
+	}
+
+	return &catalog, nil
+}
+
+// index maps the keys of every name and alias, rejecting the ambiguous ones.
+func (c *Catalog) index() error {
+	var errs []error
+
+	if len(c.Brands) == 0 {
+		errs = append(errs, errors.New("no brands"))
+	}
+
+	c.brands = make(map[string]*VehicleBrand)
+
+	for _, brand := range c.Brands {
+		for _, name := range append([]string{brand.Name}, brand.Aliases...) {
+			key := catalogKey(name)
+
+			if other, ok := c.brands[key]; ok && other != brand {
+				errs = append(errs, fmt.Errorf("brand %q: %q is also %s", brand.Name, name, other.Name))
+				continue
+			}
+
+			if key == "" {
+				errs = append(errs, fmt.Errorf("brand %q: empty name or alias", brand.Name))
+				continue
+			}
+
+			c.brands[key] = brand
+		}
+
+		brand.models = make(map[string]*VehicleModel)
+
+		for _, model := range brand.Models {
+			for _, name := range append([]string{model.Name}, model.Aliases...) {
+				key := catalogKey(name)
+
+				if other, ok := brand.models[key]; ok && other != model {
+					errs = append(errs, fmt.Errorf("brand %q: model %q: %q is also %s", brand.Name, model.Name, name, other.Name))
+					continue
+				}
+
+				if key == "" {
+					errs = append(errs, fmt.Errorf("brand %q: model %q: empty name or alias", brand.Name, model.Name))
+					continue
+				}
+
+				brand.models[key] = model
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Models returns the models of a brand, resolved like in Resolve.
+func (c *Catalog) Models(brand string) ([]*VehicleModel, error) {
+	match, ok := c.Resolve(brand, "")
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownBrand, brand)
+	}
+
+	return match.Brand.Models, nil
+}
+
+// Resolve returns the catalog brand and model closest to brand and model.
+// Without a brand the model is looked up in every brand, and only resolves
+// when a single brand has it. Names match exactly first; otherwise the
+// closest name or alias within a couple of typos is used.
+func (c *Catalog) Resolve(brand string, model string) (VehicleMatch, bool) {
+	if c == nil {
+		return VehicleMatch{}, false
+	}
+
+	brandKey, modelKey := catalogKey(brand), catalogKey(model)
+
+	if brandKey == "" {
+		return c.resolveModel(modelKey)
+	}
+
+	vehicleBrand, score := closest(c.brands, brandKey)
+	if vehicleBrand == nil {
+		return VehicleMatch{}, false
+	}
+
+	match := VehicleMatch{Brand: vehicleBrand, Score: score}
+
+	if vehicleModel, modelScore := closest(vehicleBrand.models, modelKey); vehicleModel != nil {
+		match.Model = vehicleModel
+		match.Score = min(score, modelScore)
+	}
+
+	return match, true
+}
+
+// resolveModel finds the brand of a model, when only one has it.
+func (c *Catalog) resolveModel(modelKey string) (VehicleMatch, bool) {
+	var matches []VehicleMatch
+
+	for _, brand := range c.Brands {
+		if model, score := closest(brand.models, modelKey); model != nil {
+			matches = append(matches, VehicleMatch{Brand: brand, Model: model, Score: score})
+		}
+	}
+
+	if len(matches) == 0 {
+		return VehicleMatch{}, false
+	}
+
+	best := matches[0]
+	tied := false
+
+	for _, match := range matches[1:] {
+		switch {
+		case match.Score > best.Score:
+			best, tied = match, false
+		case match.Score == best.Score:
+			tied = true
+		}
+	}
+
+	return best, !tied
+}
+
+// ResolveTitle finds the brand and model named in a listing title, e.g.
+// "Toyota Yaris 2019 full". Only names and aliases written out in full match,
+// the brand first.
+func (c *Catalog) ResolveTitle(title string) (VehicleMatch, bool) {
+	if c == nil {
+		return VehicleMatch{}, false
+	}
+
+	words := strings.Fields(title)
+
+	for i := range words {
+		// brands and models take up to three words, e.g. "Land Cruiser Prado"
+		for n := min(maxNameWords, len(words)-i); n > 0; n-- {
+			brand, ok := c.brands[catalogKey(strings.Join(words[i:i+n], " "))]
+			if !ok {
+				continue
+			}
+
+			match := VehicleMatch{Brand: brand, Score: 1}
+			rest := words[i+n:]
+
+			for m := min(maxNameWords, len(rest)); m > 0; m-- {
+				if model, ok := brand.models[catalogKey(strings.Join(rest[:m], " "))]; ok {
+					match.Model = model
+					break
+				}
+			}
+
+			return match, true
+		}
+	}
+
+	return VehicleMatch{}, false
+}
+
+// Canonical returns filter with the catalog names of its brand and model,
+// or filter itself when they do not resolve.
+func (c *Catalog) Canonical(filter dtos.AutoFilter) dtos.AutoFilter {
+	match, ok := c.Resolve(filter.Brand, filter.Model)
+	if !ok || filter.Brand == "" {
+		return filter
+	}
+
+	filter.Brand = match.Brand.Name
+
+	if match.Model != nil {
+		filter.Model = match.Model.Name
+	}
+
+	return filter
+}
+
+// WithCatalog resolves the brands and models of the searches in catalog, to
+// build the search URLs with the slugs of each source.
+func WithCatalog(catalog *Catalog) Option {
+	return func(o *scrapperOptions) {
+		o.catalog = catalog
+	}
+}
+
+// closest returns the entry of index closest to key, with its score, or nil
+// when none is within the typos allowed for the key length.
+func closest[T any](index map[string]*T, key string) (*T, float64) {
+	if key == "" {
+		return nil, 0
+	}
+
+	if entry, ok := index[key]; ok {
+		return entry, 1
+	}
+
+	typos := allowedTypos(key)
+
+	var best *T
+	bestDistance := typos + 1
+
+	// in key order, so ties resolve the same way on every run
+	for _, candidate := range slices.Sorted(maps.Keys(index)) {
+		if distance := levenshtein(key, candidate); distance < bestDistance {
+			best, bestDistance = index[candidate], distance
+		}
+	}
+
+	if best == nil {
+		return nil, 0
+	}
+
+	return best, 1 - float64(bestDistance)/float64(len([]rune(key)))
+}
+
+// allowedTypos is how many edits a key may be from a name: none for short
+// ones like "vw" or "x5", where an edit makes another name.
+func allowedTypos(key string) int {
+	switch n := len([]rune(key)); {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	}
+
+	return 2
+}
+
+// catalogKey folds a name for matching: lowercase, without accents, spaces
+// or punctuation.
+func catalogKey(name string) string {
+	var key strings.Builder
+
+	for _, r := range foldAccents(strings.ToLower(name)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			key.WriteRune(r)
+		}
+	}
+
+	return key.String()
+}
+
+// foldAccents removes the accents of s, so "Citroën" reads "Citroen".
+func foldAccents(s string) string {
+	var folded strings.Builder
+
+	for _, r := range norm.NFD.String(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			folded.WriteRune(r)
+		}
+	}
+
+	return norm.NFC.String(folded.String())
+}
+
+// levenshtein is the number of single rune edits between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/enums"
+)
+
+func TestCatalogResolve(t *testing.T) {
+	catalog, err := LoadCatalog("")
+	if err != nil {
+		t.Fatalf("LoadCatalog() error = %v", err)
+	}
+
+	tests := []struct {
+		brand, model         string
+		wantBrand, wantModel string
+		wantOK               bool
+	}{
+		{brand: "VW", model: "golf", wantBrand: "Volkswagen", wantModel: "Golf", wantOK: true},
+		{brand: "Volkswagen", model: "", wantBrand: "Volkswagen", wantOK: true},
+		{brand: "Mercedes Benz", model: "C-Class", wantBrand: "Mercedes-Benz", wantModel: "Clase C", wantOK: true},
+		{brand: "mercedes-benz", model: "clase c", wantBrand: "Mercedes-Benz", wantModel: "Clase C", wantOK: true},
+		{brand: "Mercedez Benz", model: "", wantBrand: "Mercedes-Benz", wantOK: true},
+		{brand: "Toyta", model: "Yaris", wantBrand: "Toyota", wantModel: "Yaris", wantOK: true},
+		{brand: "Kia", model: "Río", wantBrand: "Kia", wantModel: "Rio", wantOK: true},
+		{brand: "Kia", model: "Stinger", wantBrand: "Kia", wantOK: true},
+		{brand: "", model: "Hilux", wantBrand: "Toyota", wantModel: "Hilux", wantOK: true},
+		{brand: "", model: "cx5", wantBrand: "Mazda", wantModel: "CX-5", wantOK: true},
+		{brand: "Ferrari", model: "F40"},
+		{brand: "VWW", model: ""},
+	}
+
+	for _, tt := range tests {
+		match, ok := catalog.Resolve(tt.brand, tt.model)
+		if ok != tt.wantOK {
+			t.Errorf("Resolve(%q, %q) ok = %v, want %v", tt.brand, tt.model, ok, tt.wantOK)
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		model := ""
+		if match.Model != nil {
+			model = match.Model.Name
+		}
+
+		if match.Brand.Name != tt.wantBrand || model != tt.wantModel {
+			t.Errorf("Resolve(%q, %q) = %s %s, want %s %s", tt.brand, tt.model, match.Brand.Name, model, tt.wantBrand, tt.wantModel)
+		}
+	}
+}
+
+func TestParseCatalogRejectsAmbiguousAliases(t *testing.T) {
+	_, err := ParseCatalog([]byte(`
+brands:
+  - name: Mercedes-Benz
+    aliases: [MB]
+  - name: MB Motors
+    aliases: [M-B]
+`))
+	if err == nil || !strings.Contains(err.Error(), `"M-B" is also Mercedes-Benz`) {
+		t.Errorf("ParseCatalog() error = %v, want the ambiguous alias", err)
+	}
+}
+
+func TestBuildURLWithCatalog(t *testing.T) {
+	profiles := newTestProfileStore(t)
+
+	profile, err := profiles.Profile(enums.NeoAuto.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	catalog, err := ParseCatalog([]byte(`
+brands:
+  - name: Volkswagen
+    aliases: [VW]
+    models:
+      - name: T-Cross
+  - name: Mercedes-Benz
+    aliases: [Mercedes Benz]
+    slugs:
+      NeoAuto: mercedes
+    models:
+      - name: Clase C
+        aliases: [C-Class]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filter dtos.AutoFilter
+		want   string
+	}{
+		{
+			filter: dtos.AutoFilter{Brand: "VW", Model: "t cross"},
+			want:   "https://www.neoauto.com/venta-de-autos-usados-volkswagen-t-cross",
+		},
+		{
+			filter: dtos.AutoFilter{Brand: "mercedes benz", Model: "C-Class"},
+			want:   "https://www.neoauto.com/venta-de-autos-usados-mercedes-clase-c",
+		},
+		{
+			filter: dtos.AutoFilter{Brand: "Citroën", Model: "C3 Aircross"},
+			want:   "https://www.neoauto.com/venta-de-autos-usados-citroen-c3-aircross",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := profile.BuildURL(tt.filter, "", catalog)
+		if err != nil {
+			t.Fatalf("BuildURL() error = %v", err)
+		}
+
+		if got != tt.want {
+			t.Errorf("BuildURL(%+v) = %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogResolveTitle(t *testing.T) {
+	catalog, err := LoadCatalog("")
+	if err != nil {
+		t.Fatalf("LoadCatalog() error = %v", err)
+	}
+
+	tests := []struct {
+		title                string
+		wantBrand, wantModel string
+		wantOK               bool
+	}{
+		{title: "Toyota Land Cruiser Prado 2015 4x4", wantBrand: "Toyota", wantModel: "Land Cruiser Prado", wantOK: true},
+		{title: "Vendo Mercedes Benz Clase C 2019", wantBrand: "Mercedes-Benz", wantModel: "Clase C", wantOK: true},
+		{title: "Toyota Corolla Cross híbrido", wantBrand: "Toyota", wantModel: "Corolla Cross", wantOK: true},
+		{title: "Kia Stinger GT", wantBrand: "Kia", wantOK: true},
+		{title: "Toyta Yaris"},
+	}
+
+	for _, tt := range tests {
+		match, ok := catalog.ResolveTitle(tt.title)
+		if ok != tt.wantOK {
+			t.Errorf("ResolveTitle(%q) ok = %v, want %v", tt.title, ok, tt.wantOK)
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		model := ""
+		if match.Model != nil {
+			model = match.Model.Name
+		}
+
+		if match.Brand.Name != tt.wantBrand || model != tt.wantModel {
+			t.Errorf("ResolveTitle(%q) = %s %s, want %s %s", tt.title, match.Brand.Name, model, tt.wantBrand, tt.wantModel)
+		}
+	}
+}
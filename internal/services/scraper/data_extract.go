@@ -0,0 +1,373 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// DataSpec maps listings a site ships as JSON instead of walking its markup.
+// The JSON is the text of the Script elements, narrowed to the first group of
+// Pattern when set (e.g. "window.__STATE__ = (.*);"), or the XHR and fetch
+// responses whose URL contains Response. Items is the dotted path of the
+// listings in the JSON and Fields the dotted paths of their fields, in the
+// json key of each field. Markup extraction remains the fallback.
+type DataSpec struct {
+	Script   string               `yaml:"script"`
+	Pattern  string               `yaml:"pattern"`
+	Response string               `yaml:"response"`
+	Items    string               `yaml:"items"`
+	Fields   map[string]FieldSpec `yaml:"fields"`
+
+	pattern *regexp.Regexp
+}
+
+// Enabled reports whether the profile declares listings data.
+func (d DataSpec) Enabled() bool {
+	return d.Items != ""
+}
+
+func (d *DataSpec) compile() error {
+	if !d.Enabled() {
+		return nil
+	}
+
+	var errs []error
+
+	if d.Script == "" && d.Response == "" {
+		errs = append(errs, errors.New("script or response is required"))
+	}
+
+	if d.Pattern != "" {
+		pattern, err := regexp.Compile("(?s)" + d.Pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pattern: %w", err))
+		}
+
+		d.pattern = pattern
+	}
+
+	for _, name := range []string{FieldURL, FieldTitle, FieldPrice} {
+		if field, ok := d.Fields[name]; !ok || field.JSON == "" {
+			errs = append(errs, fmt.Errorf("fields.%s.json is required", name))
+		}
+	}
+
+	for name, field := range d.Fields {
+		transforms, err := compileTransforms(field.Transforms)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fields.%s: %w", name, err))
+		}
+
+		field.transforms = transforms
+		d.Fields[name] = field
+	}
+
+	return errors.Join(errs...)
+}
+
+// scriptPayloads returns the JSON held by the texts of the script elements.
+func (d DataSpec) scriptPayloads(texts []string) []string {
+	payloads := make([]string, 0, len(texts))
+
+	for _, text := range texts {
+		if d.pattern != nil {
+			match := d.pattern.FindStringSubmatch(text)
+			if match == nil {
+				continue
+			}
+
+			text = match[len(match)-1]
+		}
+
+		payloads = append(payloads, text)
+	}
+
+	return payloads
+}
+
+// listings maps the listings found in the JSON payloads. Listings without
+// URL, title or price are skipped, like in the markup.
+func (d DataSpec) listings(payloads []string, pageURL string, e *extraction) []*dtos.AutoFilterResponse {
+	autos := make([]*dtos.AutoFilterResponse, 0)
+	seen := make(map[string]bool)
+
+	for _, payload := range payloads {
+		var document any
+		if err := json.Unmarshal([]byte(payload), &document); err != nil {
+			continue
+		}
+
+		for _, item := range jsonLookup(document, jsonPath(d.Items)) {
+			auto, ok := d.listing(item, pageURL, e)
+			if !ok {
+				continue
+			}
+
+			// the same listing often ships in the page and in a response
+			if seen[auto.URL] {
+				e.skip(skipDuplicate)
+				continue
+			}
+
+			seen[auto.URL] = true
+			autos = append(autos, auto)
+			e.done()
+		}
+	}
+
+	return autos
+}
+
+func (d DataSpec) listing(item any, pageURL string, e *extraction) (*dtos.AutoFilterResponse, bool) {
+	e.item()
+
+	url, err := d.field(item, FieldURL, pageURL)
+	if !e.require(FieldURL, err) {
+		return nil, false
+	}
+
+	title, err := d.field(item, FieldTitle, pageURL)
+	if !e.require(FieldTitle, err) {
+		return nil, false
+	}
+
+	textPrice, err := d.field(item, FieldPrice, pageURL)
+
+	var price float64
+	if err == nil {
+		price, err = strconv.ParseFloat(textPrice, 64)
+	}
+
+	if !e.require(FieldPrice, err) {
+		return nil, false
+	}
+
+	imageURL, err := d.field(item, FieldImage, pageURL)
+	e.optional(FieldImage, err)
+
+	imagesField := d.Fields[FieldImages]
+
+	var values []string
+	if imagesField.JSON != "" {
+		values = imagesField.nodeValues(item)
+	}
+
+	images := listingImages(imageURL, values, imagesField, pageURL)
+
+	if imageURL == "" && len(images) > 0 {
+		imageURL = images[0]
+	}
+
+	return &dtos.AutoFilterResponse{
+		Title:     title,
+		Price:     price,
+		URL:       url,
+		ImageURL:  imageURL,
+		ImageURLs: images,
+	}, true
+}
+
+func (d DataSpec) field(item any, name string, pageURL string) (string, error) {
+	field, ok := d.Fields[name]
+	if !ok || field.JSON == "" {
+		return "", fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+	}
+
+	values := field.nodeValues(item)
+	if len(values) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+	}
+
+	return field.Apply(values[0], pageURL)
+}
+
+// staticListings returns the listings embedded in the scripts of document.
+func (d DataSpec) staticListings(document *goquery.Document, pageURL string, e *extraction) []*dtos.AutoFilterResponse {
+	if !d.Enabled() || d.Script == "" {
+		return nil
+	}
+
+	var texts []string
+	document.Find(d.Script).Each(func(_ int, script *goquery.Selection) {
+		texts = append(texts, script.Text())
+	})
+
+	return d.listings(d.scriptPayloads(texts), pageURL, e)
+}
+
+// dataCaptureWait bounds how long the listings wait for the bodies of the
+// matching responses still loading.
+const dataCaptureWait = 5 * time.Second
+
+// dataCapture collects the JSON responses of a page matching a DataSpec.
+// Responses are pending from their headers until their body is read, so the
+// listings never miss a body still being fetched.
+type dataCapture struct {
+	spec DataSpec
+
+	mu       sync.Mutex
+	payloads []string
+	pending  int
+	// settled is closed while no response is pending.
+	settled chan struct{}
+}
+
+func newDataCapture(spec DataSpec) *dataCapture {
+	settled := make(chan struct{})
+	close(settled)
+
+	return &dataCapture{spec: spec, settled: settled}
+}
+
+// expect marks a matching response as pending.
+func (c *dataCapture) expect() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.pending == 0 {
+		c.settled = make(chan struct{})
+	}
+
+	c.pending++
+}
+
+// receive settles a pending response, keeping its payload unless it failed.
+func (c *dataCapture) receive(payload string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if ok {
+		c.payloads = append(c.payloads, payload)
+	}
+
+	c.pending--
+	if c.pending == 0 {
+		close(c.settled)
+	}
+}
+
+// wait blocks until no response is pending, for at most timeout.
+func (c *dataCapture) wait(timeout time.Duration) {
+	c.mu.Lock()
+	settled := c.settled
+	c.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-settled:
+	case <-timer.C:
+	}
+}
+
+// captureData starts collecting the responses of page for spec. It must run
+// before the page navigates; the returned function stops it.
+func captureData(page *rod.Page, spec DataSpec) (*dataCapture, func()) {
+	capture := newDataCapture(spec)
+
+	if !spec.Enabled() || spec.Response == "" {
+		return capture, func() {}
+	}
+
+	if err := (proto.NetworkEnable{}).Call(page); err != nil {
+		return capture, func() {}
+	}
+
+	events, cancel := page.WithCancel()
+
+	// matching responses by request, until their body finished loading
+	responses := make(map[proto.NetworkRequestID]bool)
+
+	go events.EachEvent(func(e *proto.NetworkResponseReceived) {
+		if e.Type != proto.NetworkResourceTypeXHR && e.Type != proto.NetworkResourceTypeFetch {
+			return
+		}
+
+		if strings.Contains(e.Response.URL, spec.Response) {
+			responses[e.RequestID] = true
+			capture.expect()
+		}
+	}, func(e *proto.NetworkLoadingFailed) {
+		if !responses[e.RequestID] {
+			return
+		}
+		delete(responses, e.RequestID)
+
+		capture.receive("", false)
+	}, func(e *proto.NetworkLoadingFinished) {
+		if !responses[e.RequestID] {
+			return
+		}
+		delete(responses, e.RequestID)
+
+		// the body is read off the event loop, which must keep delivering
+		go func() {
+			capture.receive(responseBody(events, e.RequestID))
+		}()
+	})()
+
+	return capture, cancel
+}
+
+// responseBody reads the body of a finished response of page.
+func responseBody(page *rod.Page, requestID proto.NetworkRequestID) (string, bool) {
+	body, err := proto.NetworkGetResponseBody{RequestID: requestID}.Call(page)
+	if err != nil {
+		return "", false
+	}
+
+	if !body.Base64Encoded {
+		return body.Body, true
+	}
+
+	data, err := base64.StdEncoding.DecodeString(body.Body)
+	if err != nil {
+		return "", false
+	}
+
+	return string(data), true
+}
+
+// listings returns the listings of the responses captured so far, once those
+// still loading arrived, and of the scripts of page, then forgets the
+// responses so the next page starts anew.
+func (c *dataCapture) listings(page *rod.Page, pageURL string, e *extraction) []*dtos.AutoFilterResponse {
+	if !c.spec.Enabled() {
+		return nil
+	}
+
+	c.wait(dataCaptureWait)
+
+	c.mu.Lock()
+	payloads := c.payloads
+	c.payloads = nil
+	c.mu.Unlock()
+
+	if c.spec.Script != "" {
+		if scripts, err := page.Elements(c.spec.Script); err == nil {
+			var texts []string
+			for _, script := range scripts {
+				if text, err := script.Eval(`() => this.textContent`); err == nil {
+					texts = append(texts, text.Value.Str())
+				}
+			}
+
+			payloads = append(payloads, c.spec.scriptPayloads(texts)...)
+		}
+	}
+
+	return c.spec.listings(payloads, pageURL, e)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+)
+
+const dataProfile = `
+name: Autos
+base_url: https://autos.example/
+search:
+  template: "{{.BaseURL}}buscar"
+list_container: div.results
+item: article
+fields:
+  url: {selectors: [a], extract: "attr:href", transforms: [absolute_url]}
+  title: {selectors: [h2], transforms: [trim]}
+  image: {selectors: [img], extract: "attr:src"}
+  price: {selectors: [span.price], transforms: [price]}
+data:
+  script: script
+  pattern: 'window\.__STATE__\s*=\s*(\{.*\});'
+  items: search.results
+  fields:
+    url: {json: slug, transforms: [absolute_url]}
+    title: {json: name, transforms: [trim]}
+    price: {json: price.amount}
+    image: {json: photos.url}
+    images: {json: photos.url, transforms: [absolute_url]}
+`
+
+// dataPage has its listings only in the embedded state, with one incomplete
+// listing, and a markup listing that must be ignored.
+const dataPage = `<!DOCTYPE html><html><body>
+<div class="results"><article><a href="/markup">Markup</a><h2>Markup</h2><span class="price">S/ 1</span></article></div>
+<script>window.__STATE__ = {"search": {"results": [
+  {"slug": "/autos/kia-rio-2018", "name": " Kia Rio 2018 ", "price": {"amount": 9800}, "photos": [{"url": "https://img.example/1.jpg"}, {"url": "/fotos/2.jpg"}]},
+  {"slug": "/autos/sin-precio", "name": "Sin precio"},
+  {"slug": "/autos/mazda-3-2019", "name": "Mazda 3 2019", "price": {"amount": 16300.5}}
+]}};</script>
+</body></html>`
+
+func TestHTTPScrapperExtractsPageData(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "autos.yaml"), []byte(dataProfile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := NewProfileStore(dir)
+	if err != nil {
+		t.Fatalf("NewProfileStore() error = %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(dataPage))
+	}))
+	t.Cleanup(srv.Close)
+
+	autos, err := NewHTTPScrapper(profiles, "Autos", WithBaseURL(srv.URL+"/")).FindByFilter(context.Background(), dtos.AutoFilter{})
+	if err != nil {
+		t.Fatalf("FindByFilter() error = %v", err)
+	}
+
+	want := []*dtos.AutoFilterResponse{
+		{
+			Title:     "Kia Rio 2018",
+			Price:     9800,
+			URL:       srv.URL + "/autos/kia-rio-2018",
+			ImageURL:  "https://img.example/1.jpg",
+			ImageURLs: []string{"https://img.example/1.jpg", srv.URL + "/fotos/2.jpg"},
+		},
+		{
+			Title:     "Mazda 3 2019",
+			Price:     16300.5,
+			URL:       srv.URL + "/autos/mazda-3-2019",
+			ImageURLs: []string{},
+		},
+	}
+
+	if !reflect.DeepEqual(autos, want) {
+		for _, auto := range autos {
+			t.Logf("got %+v", *auto)
+		}
+		t.Fatalf("FindByFilter() returned %d listings, want %d from the page data", len(autos), len(want))
+	}
+}
+
+func TestDataSpecRequiresFieldPaths(t *testing.T) {
+	profile, err := ParseSiteProfile([]byte(strings.Replace(dataProfile, "price: {json: price.amount}", "price: {selectors: [span]}", 1)))
+	if err == nil || !strings.Contains(err.Error(), "data: fields.price.json is required") {
+		t.Fatalf("ParseSiteProfile() = %v, %v, want a missing price path error", profile, err)
+	}
+}
+
+func TestDataCaptureWaitsForPendingResponses(t *testing.T) {
+	capture := newDataCapture(DataSpec{Items: "results"})
+
+	// nothing pending, nothing to wait for
+	start := time.Now()
+	capture.wait(time.Second)
+
+	if waited := time.Since(start); waited > 100*time.Millisecond {
+		t.Fatalf("wait() with nothing pending took %s", waited)
+	}
+
+	capture.expect()
+	capture.expect()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		capture.receive(`{"results":[]}`, true)
+		capture.receive("", false)
+	}()
+
+	capture.wait(5 * time.Second)
+
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+
+	if capture.pending != 0 || len(capture.payloads) != 1 {
+		t.Errorf("after wait() %d responses pending with %d payloads, want none pending and 1 payload", capture.pending, len(capture.payloads))
+	}
+}
+
+func TestDataCaptureWaitGivesUp(t *testing.T) {
+	capture := newDataCapture(DataSpec{Items: "results"})
+	capture.expect()
+
+	start := time.Now()
+	capture.wait(20 * time.Millisecond)
+
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("wait() for a response that never finishes took %s", waited)
+	}
+}
@@ -0,0 +1,656 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"golang.org/x/net/html/charset"
+	"gopkg.in/yaml.v3"
+)
+
+// Feed formats.
+const (
+	FeedCSV  = "csv"
+	FeedXML  = "xml"
+	FeedJSON = "json"
+)
+
+// Fields a feed may map besides the listing fields.
+const (
+	FieldBrand      = "brand"
+	FieldModel      = "model"
+	FieldYear       = "year"
+	FieldKilometers = "kilometers"
+)
+
+const (
+	defaultFeedInterval = time.Hour
+	feedTimeout         = time.Minute
+	maxFeedSize         = 50 << 20
+
+	// backendFeed is the backend of the feed ingestion runs.
+	backendFeed = "feed"
+)
+
+// ErrFeedNotIngested is returned by a feed searched before its first
+// successful ingestion.
+var ErrFeedNotIngested = errors.New("feed not ingested yet")
+
+// feedFields are the fields a feed may map; url, title and price are
+// required.
+var feedFields = []string{FieldURL, FieldTitle, FieldPrice, FieldImage, FieldImages, FieldBrand, FieldModel, FieldYear, FieldKilometers}
+
+// FeedSpec describes the inventory feed of a dealer. The feed at URL is
+// downloaded every Interval and parsed as Format. CSV feeds have a header
+// row and map fields by column name. XML and JSON feeds list their vehicles
+// at the dotted Items path (e.g. "inventory.vehicle") and map fields by
+// dotted paths relative to each vehicle, XML attributes being "@name".
+type FeedSpec struct {
+	Name      string               `yaml:"name"`
+	URL       string               `yaml:"url"`
+	Format    string               `yaml:"format"`
+	Interval  time.Duration        `yaml:"interval"`
+	Delimiter string               `yaml:"delimiter"`
+	Items     string               `yaml:"items"`
+	Fields    map[string]FeedField `yaml:"fields"`
+}
+
+// FeedField maps a listing field to a feed column. Values holding several
+// images are split by Separator. Transforms are those of the site profiles,
+// absolute_url resolving against the feed URL.
+type FeedField struct {
+	Column     string   `yaml:"column"`
+	Separator  string   `yaml:"separator"`
+	Transforms []string `yaml:"transforms"`
+
+	spec FieldSpec
+}
+
+// Validate checks the feed and compiles its fields.
+func (f *FeedSpec) Validate() error {
+	var errs []error
+
+	if f.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+
+	if f.URL == "" {
+		errs = append(errs, errors.New("url is required"))
+	} else if u, err := url.ParseRequestURI(f.URL); err != nil {
+		errs = append(errs, fmt.Errorf("url: %w", err))
+	} else if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("url %q is not an absolute http URL", f.URL))
+	}
+
+	switch f.Format {
+	case FeedCSV, FeedXML, FeedJSON:
+	default:
+		errs = append(errs, fmt.Errorf("unknown format %q", f.Format))
+	}
+
+	if f.Delimiter != "" && f.Format != FeedCSV {
+		errs = append(errs, errors.New("delimiter is only used by csv feeds"))
+	}
+
+	if len([]rune(f.Delimiter)) > 1 {
+		errs = append(errs, fmt.Errorf("delimiter %q is not a single character", f.Delimiter))
+	}
+
+	for _, name := range []string{FieldURL, FieldTitle, FieldPrice} {
+		if field, ok := f.Fields[name]; !ok || field.Column == "" {
+			errs = append(errs, fmt.Errorf("fields.%s.column is required", name))
+		}
+	}
+
+	for name, field := range f.Fields {
+		if !slices.Contains(feedFields, name) {
+			errs = append(errs, fmt.Errorf("unknown field %q", name))
+			continue
+		}
+
+		transforms, err := compileTransforms(field.Transforms)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fields.%s: %w", name, err))
+		}
+
+		field.spec = FieldSpec{JSON: field.Column, transforms: transforms}
+		f.Fields[name] = field
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("feed %q: %w", f.Name, errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func (f FeedSpec) interval() time.Duration {
+	if f.Interval <= 0 {
+		return defaultFeedInterval
+	}
+
+	return f.Interval
+}
+
+// LoadFeeds reads and validates the YAML list of feeds in path. An empty path
+// means no feeds. Feed names are source names, so they may not repeat, nor
+// take one of the reserved names of the other sources, ignoring case.
+func LoadFeeds(path string, reserved []string) ([]FeedSpec, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var feeds []FeedSpec
+	if err := yaml.Unmarshal(data, &feeds); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var errs []error
+
+	taken := make(map[string]bool, len(reserved))
+	for _, name := range reserved {
+		taken[strings.ToLower(name)] = true
+	}
+
+	names := make(map[string]bool)
+
+	for i := range feeds {
+		if err := feeds[i].Validate(); err != nil {
+			errs = append(errs, err)
+		}
+
+		name := strings.ToLower(feeds[i].Name)
+
+		switch {
+		case names[name]:
+			errs = append(errs, fmt.Errorf("feed %q is declared twice", feeds[i].Name))
+		case taken[name]:
+			errs = append(errs, fmt.Errorf("feed %q takes the name of another source", feeds[i].Name))
+		}
+
+		names[name] = true
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%s: %w", path, errors.Join(errs...))
+	}
+
+	return feeds, nil
+}
+
+// FeedSource is the AutoScrapper of a dealer feed. It answers searches from
+// the listings of its last successful ingestion.
+type FeedSource struct {
+	spec    FeedSpec
+	options scrapperOptions
+	client  *http.Client
+
+	mu         sync.RWMutex
+	listings   []*dtos.AutoFilterResponse
+	ingestedAt time.Time
+	lastErr    error
+}
+
+// NewFeedSource returns the source of a validated feed.
+func NewFeedSource(spec FeedSpec, opts ...Option) *FeedSource {
+	return &FeedSource{
+		spec:    spec,
+		options: newScrapperOptions(opts),
+		client:  &http.Client{Timeout: feedTimeout},
+	}
+}
+
+// Name returns the source name of the feed.
+func (s *FeedSource) Name() string {
+	return s.spec.Name
+}
+
+func (s *FeedSource) FindByFilter(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.ingestedAt.IsZero() {
+		if s.lastErr != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFeedNotIngested, s.lastErr)
+		}
+
+		return nil, ErrFeedNotIngested
+	}
+
+	autos := make([]*dtos.AutoFilterResponse, 0)
+
+	for _, listing := range s.listings {
+		if listingMatches(listing, filter) {
+			auto := *listing
+			autos = append(autos, &auto)
+		}
+	}
+
+	return autos, nil
+}
+
+// Watch ingests the feed now and then every interval, until ctx is done.
+func (s *FeedSource) Watch(ctx context.Context) {
+	ticker := time.NewTicker(s.spec.interval())
+	defer ticker.Stop()
+
+	for {
+		if err := s.Ingest(ctx); err != nil {
+			log.Printf("feed %s: keeping previous listings: %v", s.spec.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Ingest downloads and parses the feed, replacing the listings when it
+// succeeds.
+func (s *FeedSource) Ingest(ctx context.Context) (err error) {
+	run := s.options.startRun(s.spec.Name, backendFeed, dtos.AutoFilter{})
+	run.URL = s.spec.URL
+	run.Pages = 1
+
+	e := newExtraction()
+
+	var listings []*dtos.AutoFilterResponse
+
+	defer func() {
+		err = s.options.finishRun(run, e, len(listings), err)
+	}()
+
+	body, err := s.download(ctx)
+	if err != nil {
+		s.fail(err)
+		return err
+	}
+
+	run.bytes.Add(int64(len(body)))
+
+	records, err := s.records(body)
+	if err != nil {
+		s.fail(err)
+		return err
+	}
+
+	listings = make([]*dtos.AutoFilterResponse, 0, len(records))
+	seen := make(map[string]bool)
+
+	for _, record := range records {
+		listing, ok := s.listing(record, e)
+		if !ok {
+			continue
+		}
+
+		if seen[listing.URL] {
+			e.skip(skipDuplicate)
+			continue
+		}
+
+		seen[listing.URL] = true
+		listings = append(listings, listing)
+		e.done()
+	}
+
+	if s.options.quality != nil {
+		s.options.quality.record(s.spec.Name, QualityPolicy{}, e)
+	}
+
+	s.mu.Lock()
+	s.listings = listings
+	s.ingestedAt = time.Now()
+	s.lastErr = nil
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *FeedSource) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastErr = err
+}
+
+func (s *FeedSource) download(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.spec.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{code: resp.StatusCode, url: s.spec.URL}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) > maxFeedSize {
+		return nil, fmt.Errorf("feed larger than %d bytes", maxFeedSize)
+	}
+
+	return body, nil
+}
+
+// feedRecord is a vehicle of a feed.
+type feedRecord interface {
+	// values returns the usable values of a field of the vehicle.
+	values(field FeedField) []string
+}
+
+// csvRecord is a row of a CSV feed, read through the header columns.
+type csvRecord struct {
+	columns map[string]int
+	row     []string
+}
+
+func (r csvRecord) values(field FeedField) []string {
+	i, ok := r.columns[field.Column]
+	if !ok || i >= len(r.row) {
+		return nil
+	}
+
+	return field.spec.usable([]string{r.row[i]})
+}
+
+// treeRecord is a vehicle of an XML or JSON feed.
+type treeRecord struct {
+	node any
+}
+
+func (r treeRecord) values(field FeedField) []string {
+	return field.spec.nodeValues(r.node)
+}
+
+func (s *FeedSource) records(body []byte) ([]feedRecord, error) {
+	switch s.spec.Format {
+	case FeedCSV:
+		return s.csvRecords(body)
+	case FeedXML:
+		document, err := xmlTree(body)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.treeRecords(document), nil
+	default:
+		var document any
+		if err := json.Unmarshal(body, &document); err != nil {
+			return nil, err
+		}
+
+		return s.treeRecords(document), nil
+	}
+}
+
+func (s *FeedSource) csvRecords(body []byte) ([]feedRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	if s.spec.Delimiter != "" {
+		reader.Comma = []rune(s.spec.Delimiter)[0]
+	}
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("csv feed without header")
+	}
+
+	columns := make(map[string]int, len(rows[0]))
+	for i, column := range rows[0] {
+		columns[strings.TrimSpace(column)] = i
+	}
+
+	for name, field := range s.spec.Fields {
+		if _, ok := columns[field.Column]; !ok {
+			return nil, fmt.Errorf("column %q of field %s not in the header", field.Column, name)
+		}
+	}
+
+	records := make([]feedRecord, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		records = append(records, csvRecord{columns: columns, row: row})
+	}
+
+	return records, nil
+}
+
+func (s *FeedSource) treeRecords(document any) []feedRecord {
+	nodes := jsonLookup(document, jsonPath(s.spec.Items))
+
+	records := make([]feedRecord, 0, len(nodes))
+	for _, node := range nodes {
+		records = append(records, treeRecord{node: node})
+	}
+
+	return records
+}
+
+// xmlTree converts an XML document to the generic tree of a JSON document:
+// elements are objects keyed by child name, repeated children are arrays,
+// attributes are "@name" keys and the text of elements with children or
+// attributes is the "#text" key.
+func xmlTree(body []byte) (any, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	// dealer feeds often declare ISO-8859-1 or Windows-1252
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	type element struct {
+		name     string
+		children map[string]any
+		text     strings.Builder
+	}
+
+	root := &element{children: make(map[string]any)}
+	stack := []*element{root}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			current := &element{name: token.Name.Local, children: make(map[string]any)}
+			for _, attr := range token.Attr {
+				current.children["@"+attr.Name.Local] = attr.Value
+			}
+
+			stack = append(stack, current)
+		case xml.CharData:
+			stack[len(stack)-1].text.Write(token)
+		case xml.EndElement:
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			var value any = current.children
+			text := strings.TrimSpace(current.text.String())
+
+			if len(current.children) == 0 {
+				value = text
+			} else if text != "" {
+				current.children["#text"] = text
+			}
+
+			parent := stack[len(stack)-1].children
+
+			switch existing := parent[current.name].(type) {
+			case nil:
+				parent[current.name] = value
+			case []any:
+				parent[current.name] = append(existing, value)
+			default:
+				parent[current.name] = []any{existing, value}
+			}
+		}
+	}
+
+	return root.children, nil
+}
+
+// listing maps a feed record. Records without URL, title or price are
+// skipped.
+func (s *FeedSource) listing(record feedRecord, e *extraction) (*dtos.AutoFilterResponse, bool) {
+	e.item()
+
+	url, err := s.field(record, FieldURL)
+	if !e.require(FieldURL, err) {
+		return nil, false
+	}
+
+	title, err := s.field(record, FieldTitle)
+	if !e.require(FieldTitle, err) {
+		return nil, false
+	}
+
+	textPrice, err := s.field(record, FieldPrice)
+
+	var price float64
+	if err == nil {
+		price, err = strconv.ParseFloat(textPrice, 64)
+	}
+
+	if !e.require(FieldPrice, err) {
+		return nil, false
+	}
+
+	imageURL, err := s.field(record, FieldImage)
+	e.optional(FieldImage, err)
+
+	imagesField := s.spec.Fields[FieldImages]
+
+	var values []string
+	if imagesField.Column != "" {
+		for _, value := range record.values(imagesField) {
+			values = append(values, splitFeedValue(value, imagesField.Separator)...)
+		}
+	}
+
+	images := listingImages(imageURL, values, imagesField.spec, s.spec.URL)
+
+	if imageURL == "" && len(images) > 0 {
+		imageURL = images[0]
+	}
+
+	auto := &dtos.AutoFilterResponse{
+		Title:     title,
+		Price:     price,
+		URL:       url,
+		ImageURL:  imageURL,
+		ImageURLs: images,
+	}
+
+	auto.Brand, _ = s.field(record, FieldBrand)
+	auto.Model, _ = s.field(record, FieldModel)
+
+	if year, err := s.field(record, FieldYear); err == nil {
+		auto.Year = feedNumber(year)
+	}
+
+	if kilometers, err := s.field(record, FieldKilometers); err == nil {
+		auto.Kilometers = feedNumber(kilometers)
+	}
+
+	return auto, true
+}
+
+// field returns the first value of a field of record, transformed.
+func (s *FeedSource) field(record feedRecord, name string) (string, error) {
+	field, ok := s.spec.Fields[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+	}
+
+	values := record.values(field)
+	if len(values) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+	}
+
+	value := splitFeedValue(values[0], field.Separator)[0]
+
+	return field.spec.Apply(value, s.spec.URL)
+}
+
+// splitFeedValue splits value by separator, dropping empty parts.
+func splitFeedValue(value string, separator string) []string {
+	if separator == "" {
+		return []string{value}
+	}
+
+	var parts []string
+	for _, part := range strings.Split(value, separator) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	if len(parts) == 0 {
+		return []string{value}
+	}
+
+	return parts
+}
+
+// feedNumber reads the digits of a value like "45,000 km", 0 when it has
+// none.
+func feedNumber(value string) uint32 {
+	number, _ := digitsNumber(value)
+	return number
+}
+
+// listingMatches reports whether a feed or imported listing is within filter.
+// Listings without brand or model are matched by title.
+func listingMatches(auto *dtos.AutoFilterResponse, filter dtos.AutoFilter) bool {
+	title := strings.ToLower(auto.Title)
+
+	if brand := strings.TrimSpace(filter.Brand); brand != "" && auto.Brand == "" && !strings.Contains(title, strings.ToLower(brand)) {
+		return false
+	}
+
+	if model := strings.TrimSpace(filter.Model); model != "" && auto.Model == "" && !strings.Contains(title, strings.ToLower(model)) {
+		return false
+	}
+
+	return matchesFilter(auto, filter)
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+)
+
+// loadTestFeeds loads testdata/feeds/feeds.yaml with the feeds served by srv.
+func loadTestFeeds(t *testing.T, srv *httptest.Server) map[string]FeedSpec {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "feeds", "feeds.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "feeds.yaml")
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(string(data), "{{.URL}}", srv.URL)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	feeds, err := LoadFeeds(path, nil)
+	if err != nil {
+		t.Fatalf("LoadFeeds() error = %v", err)
+	}
+
+	specs := make(map[string]FeedSpec, len(feeds))
+	for _, feed := range feeds {
+		specs[feed.Name] = feed
+	}
+
+	return specs
+}
+
+func TestFeedSourceIngest(t *testing.T) {
+	srv := httptest.NewServer(http.FileServer(http.Dir(filepath.Join("testdata", "feeds"))))
+	t.Cleanup(srv.Close)
+
+	feeds := loadTestFeeds(t, srv)
+
+	// the CSV feed links relative to itself
+	tests := []struct {
+		name string
+		site string
+	}{
+		{name: "AutosDelSurCSV", site: srv.URL},
+		{name: "AutosDelSurXML", site: "https://autosdelsur.pe"},
+		{name: "AutosDelSurJSON", site: "https://autosdelsur.pe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quality := NewQuality()
+			source := NewFeedSource(feeds[tt.name], WithQuality(quality))
+
+			if _, err := source.FindByFilter(context.Background(), dtos.AutoFilter{}); !errors.Is(err, ErrFeedNotIngested) {
+				t.Errorf("FindByFilter() before ingesting error = %v, want %v", err, ErrFeedNotIngested)
+			}
+
+			if err := source.Ingest(context.Background()); err != nil {
+				t.Fatalf("Ingest() error = %v", err)
+			}
+
+			autos, err := source.FindByFilter(context.Background(), dtos.AutoFilter{Brand: "toyota", MinYear: uint32Ptr(2015), MaxPrice: float64Ptr(15000)})
+			if err != nil {
+				t.Fatalf("FindByFilter() error = %v", err)
+			}
+
+			if len(autos) != 1 {
+				t.Fatalf("FindByFilter() = %d autos, want 1", len(autos))
+			}
+
+			got := autos[0]
+			want := &dtos.AutoFilterResponse{
+				Title:    "Toyota Yaris 2018 Sedan",
+				Price:    13900,
+				URL:      tt.site + "/autos/a101",
+				ImageURL: tt.site + "/fotos/a101-1.jpg",
+				Year:     2018,
+			}
+
+			if got.Title != want.Title || got.Price != want.Price || got.URL != want.URL || got.ImageURL != want.ImageURL || got.Year != want.Year || got.Kilometers != 45000 {
+				t.Errorf("FindByFilter()[0] = %+v, want %+v with 45000 km", got, want)
+			}
+
+			if len(got.ImageURLs) != 2 {
+				t.Errorf("ImageURLs = %v, want both photos", got.ImageURLs)
+			}
+
+			all, _ := source.FindByFilter(context.Background(), dtos.AutoFilter{})
+			if stats := quality.Sources()[0]; stats.Extracted != len(all) {
+				t.Errorf("quality extracted %d listings, want %d", stats.Extracted, len(all))
+			}
+		})
+	}
+}
+
+func TestLoadFeedsRejectsInvalidFeeds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feeds.yaml")
+	err := os.WriteFile(path, []byte(`
+- name: Broken
+  url: https://dealer.test/feed.txt
+  format: txt
+  fields:
+    title: {column: title}
+    color: {column: color}
+- name: neoauto
+  url: dealer.test/feed.csv
+  format: csv
+  fields: &fields
+    url: {column: url}
+    title: {column: title}
+    price: {column: price}
+- name: broken
+  url: https://dealer.test/feed.csv
+  format: csv
+  fields: *fields
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LoadFeeds(path, []string{"NeoAuto"})
+	if err == nil {
+		t.Fatal("LoadFeeds() error = nil, want an error")
+	}
+
+	for _, want := range []string{
+		`unknown format "txt"`,
+		"fields.url.column is required",
+		"fields.price.column is required",
+		`unknown field "color"`,
+		`feed "neoauto": url: parse "dealer.test/feed.csv"`,
+		`feed "neoauto" takes the name of another source`,
+		`feed "broken" is declared twice`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadFeeds() error = %v, want it to mention %q", err, want)
+		}
+	}
+}
+
+func TestXMLTreeDecodesDeclaredCharset(t *testing.T) {
+	body := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n<vehiculo><marca>Citro\xebn</marca></vehiculo>")
+
+	tree, err := xmlTree(body)
+	if err != nil {
+		t.Fatalf("xmlTree() error = %v", err)
+	}
+
+	if got := fmt.Sprint(tree); !strings.Contains(got, "Citroën") {
+		t.Errorf("xmlTree() = %s, want the brand decoded as Citroën", got)
+	}
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+)
+
+const (
+	// MinFilterYear is the earliest year a search may ask for.
+	MinFilterYear = 1900
+	// MaxFilterPrice is the highest price a search may ask for; higher
+	// maximum prices are clamped to it.
+	MaxFilterPrice = 100_000_000
+
+	maxFilterNameLength = 64
+)
+
+// Filter fields, as named in FindByFilterRequest.
+const (
+	FilterBrand    = "brand"
+	FilterModel    = "model"
+	FilterMinYear  = "min_year"
+	FilterMaxYear  = "max_year"
+	FilterMinPrice = "min_price"
+	FilterMaxPrice = "max_price"
+)
+
+// FieldViolation is a filter field that failed validation.
+type FieldViolation struct {
+	Field       string
+	Description string
+}
+
+// FilterError lists every invalid field of a search filter.
+type FilterError struct {
+	Violations []FieldViolation
+}
+
+func (e *FilterError) Error() string {
+	violations := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		violations = append(violations, violation.Field+": "+violation.Description)
+	}
+
+	return "invalid filter: " + strings.Join(violations, "; ")
+}
+
+// CanonicalFilter validates filter and returns its canonical form, the one
+// searches are scraped, recorded and keyed with. A *FilterError lists the
+// invalid fields: names longer than 64 characters, years before 1900 or a
+// minimum year after next year, negative or non-finite prices, and minimums
+// above their maximums.
+func CanonicalFilter(filter dtos.AutoFilter) (dtos.AutoFilter, error) {
+	canonical := canonicalFilter(filter)
+	nextYear := uint32(time.Now().Year() + 1)
+
+	var violations []FieldViolation
+
+	violate := func(field string, format string, args ...any) {
+		violations = append(violations, FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+	}
+
+	if utf8.RuneCountInString(canonical.Brand) > maxFilterNameLength {
+		violate(FilterBrand, "must be at most %d characters", maxFilterNameLength)
+	}
+
+	if utf8.RuneCountInString(canonical.Model) > maxFilterNameLength {
+		violate(FilterModel, "must be at most %d characters", maxFilterNameLength)
+	}
+
+	switch {
+	case filter.MinYear != nil && *filter.MinYear > 0 && *filter.MinYear < MinFilterYear:
+		violate(FilterMinYear, "must be %d or later", MinFilterYear)
+	case canonical.MinYear != nil && *canonical.MinYear > nextYear:
+		violate(FilterMinYear, "must be %d or earlier", nextYear)
+	case canonical.MinYear != nil && canonical.MaxYear != nil && *canonical.MinYear > *canonical.MaxYear:
+		violate(FilterMinYear, "must not be after max_year")
+	}
+
+	if filter.MaxYear != nil && *filter.MaxYear > 0 && *filter.MaxYear < MinFilterYear {
+		violate(FilterMaxYear, "must be %d or later", MinFilterYear)
+	}
+
+	switch {
+	case filter.MinPrice != nil && !validPrice(*filter.MinPrice):
+		violate(FilterMinPrice, "must be a positive number")
+	case canonical.MinPrice != nil && *canonical.MinPrice > MaxFilterPrice:
+		violate(FilterMinPrice, "must be at most %d", MaxFilterPrice)
+	case canonical.MinPrice != nil && canonical.MaxPrice != nil && *canonical.MinPrice > *canonical.MaxPrice:
+		violate(FilterMinPrice, "must not be above max_price")
+	}
+
+	if filter.MaxPrice != nil && !validPrice(*filter.MaxPrice) {
+		violate(FilterMaxPrice, "must be a positive number")
+	}
+
+	if len(violations) > 0 {
+		return canonical, &FilterError{Violations: violations}
+	}
+
+	return canonical, nil
+}
+
+// canonicalFilter trims and lowercases the names of filter, collapsing their
+// spaces, and drops its unset and invalid bounds. Maximum years after next
+// year and maximum prices above MaxFilterPrice are clamped.
+func canonicalFilter(filter dtos.AutoFilter) dtos.AutoFilter {
+	canonical := dtos.AutoFilter{
+		Brand: strings.ToLower(strings.Join(strings.Fields(filter.Brand), " ")),
+		Model: strings.ToLower(strings.Join(strings.Fields(filter.Model), " ")),
+	}
+
+	if filter.MinYear != nil && *filter.MinYear >= MinFilterYear {
+		canonical.MinYear = boundOf(*filter.MinYear)
+	}
+
+	if filter.MaxYear != nil && *filter.MaxYear >= MinFilterYear {
+		canonical.MaxYear = boundOf(min(*filter.MaxYear, uint32(time.Now().Year()+1)))
+	}
+
+	if filter.MinPrice != nil && *filter.MinPrice > 0 && validPrice(*filter.MinPrice) {
+		canonical.MinPrice = boundOf(*filter.MinPrice)
+	}
+
+	if filter.MaxPrice != nil && *filter.MaxPrice > 0 && validPrice(*filter.MaxPrice) {
+		canonical.MaxPrice = boundOf(min(*filter.MaxPrice, MaxFilterPrice))
+	}
+
+	return canonical
+}
+
+// validPrice reports whether price is a finite price bound, 0 being unset.
+func validPrice(price float64) bool {
+	return price >= 0 && !math.IsInf(price, 0)
+}
+
+func boundOf[T uint32 | float64](value T) *T {
+	return &value
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+)
+
+func TestCanonicalFilter(t *testing.T) {
+	nextYear := uint32(time.Now().Year() + 1)
+
+	got, err := CanonicalFilter(dtos.AutoFilter{
+		Brand:    "  Mercedes   BENZ ",
+		Model:    "Clase C",
+		MinYear:  uint32Ptr(0),
+		MaxYear:  uint32Ptr(2100),
+		MinPrice: float64Ptr(5000),
+		MaxPrice: float64Ptr(1e12),
+	})
+	if err != nil {
+		t.Fatalf("CanonicalFilter() error = %v", err)
+	}
+
+	want := dtos.AutoFilter{
+		Brand:    "mercedes benz",
+		Model:    "clase c",
+		MaxYear:  uint32Ptr(nextYear),
+		MinPrice: float64Ptr(5000),
+		MaxPrice: float64Ptr(MaxFilterPrice),
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CanonicalFilter() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCanonicalFilterViolations(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter dtos.AutoFilter
+		want   []string
+	}{
+		{
+			name:   "years reversed",
+			filter: dtos.AutoFilter{MinYear: uint32Ptr(2020), MaxYear: uint32Ptr(2015)},
+			want:   []string{FilterMinYear},
+		},
+		{
+			name:   "years too early",
+			filter: dtos.AutoFilter{MinYear: uint32Ptr(1800), MaxYear: uint32Ptr(1850)},
+			want:   []string{FilterMinYear, FilterMaxYear},
+		},
+		{
+			name:   "min year in the future",
+			filter: dtos.AutoFilter{MinYear: uint32Ptr(3000)},
+			want:   []string{FilterMinYear},
+		},
+		{
+			name:   "prices invalid",
+			filter: dtos.AutoFilter{MinPrice: float64Ptr(-1), MaxPrice: float64Ptr(math.NaN())},
+			want:   []string{FilterMinPrice, FilterMaxPrice},
+		},
+		{
+			name:   "prices reversed",
+			filter: dtos.AutoFilter{MinPrice: float64Ptr(20000), MaxPrice: float64Ptr(10000)},
+			want:   []string{FilterMinPrice},
+		},
+		{
+			name:   "brand too long",
+			filter: dtos.AutoFilter{Brand: strings.Repeat("toyota ", 10)},
+			want:   []string{FilterBrand},
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := CanonicalFilter(tt.filter)
+
+		var filterErr *FilterError
+		if !errors.As(err, &filterErr) {
+			t.Errorf("%s: CanonicalFilter() error = %v, want a *FilterError", tt.name, err)
+			continue
+		}
+
+		var fields []string
+		for _, violation := range filterErr.Violations {
+			fields = append(fields, violation.Field)
+		}
+
+		if !reflect.DeepEqual(fields, tt.want) {
+			t.Errorf("%s: violations of %v, want %v", tt.name, fields, tt.want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultLocale         = "es-PE"
+	defaultTimezone       = "America/Lima"
+	defaultAcceptLanguage = "es-PE,es;q=0.9"
+)
+
+// webdriverEvasion hides the automation flags headless Chromium exposes to
+// the scripts of the page. It takes the JSON list of navigator.languages.
+const webdriverEvasion = `
+Object.defineProperty(navigator, 'webdriver', { get: () => undefined });
+Object.defineProperty(navigator, 'languages', { get: () => %s });
+window.chrome = window.chrome || { runtime: {} };
+`
+
+// Fingerprint is how a browser session presents itself to the sites. Empty
+// locale, timezone and Accept-Language use the Peruvian defaults.
+type Fingerprint struct {
+	Name           string `yaml:"name"`
+	UserAgent      string `yaml:"user_agent"`
+	Platform       string `yaml:"platform"`
+	Width          int    `yaml:"width"`
+	Height         int    `yaml:"height"`
+	Locale         string `yaml:"locale"`
+	Timezone       string `yaml:"timezone"`
+	AcceptLanguage string `yaml:"accept_language"`
+}
+
+// defaultFingerprints are common desktop browsers in Peru.
+var defaultFingerprints = []Fingerprint{
+	{
+		Name:      "chrome-windows",
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+		Platform:  "Win32",
+		Width:     1920,
+		Height:    1080,
+	},
+	{
+		Name:      "chrome-windows-laptop",
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/123.0.0.0 Safari/537.36",
+		Platform:  "Win32",
+		Width:     1366,
+		Height:    768,
+	},
+	{
+		Name:      "chrome-macos",
+		UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+		Platform:  "MacIntel",
+		Width:     1440,
+		Height:    900,
+	},
+	{
+		Name:      "chrome-linux",
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+		Platform:  "Linux x86_64",
+		Width:     1600,
+		Height:    900,
+	},
+}
+
+func (f *Fingerprint) validate() error {
+	var errs []error
+
+	if f.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+
+	if f.UserAgent == "" {
+		errs = append(errs, errors.New("user_agent is required"))
+	}
+
+	if f.Width <= 0 || f.Height <= 0 {
+		errs = append(errs, errors.New("width and height must be positive"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("fingerprint %q: %w", f.Name, errors.Join(errs...))
+	}
+
+	if f.Locale == "" {
+		f.Locale = defaultLocale
+	}
+
+	if f.Timezone == "" {
+		f.Timezone = defaultTimezone
+	}
+
+	if f.AcceptLanguage == "" {
+		f.AcceptLanguage = defaultAcceptLanguage
+	}
+
+	return nil
+}
+
+// apply makes page present the fingerprint. It must run before the page
+// navigates.
+func (f Fingerprint) apply(page *rod.Page) error {
+	err := page.SetUserAgent(&proto.NetworkSetUserAgentOverride{
+		UserAgent:      f.UserAgent,
+		AcceptLanguage: f.AcceptLanguage,
+		Platform:       f.Platform,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+		Width:             f.Width,
+		Height:            f.Height,
+		DeviceScaleFactor: 1,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := (proto.EmulationSetLocaleOverride{Locale: f.Locale}).Call(page); err != nil {
+		return err
+	}
+
+	if err := (proto.EmulationSetTimezoneOverride{TimezoneID: f.Timezone}).Call(page); err != nil {
+		return err
+	}
+
+	languages, err := json.Marshal(f.languages())
+	if err != nil {
+		return err
+	}
+
+	_, err = page.EvalOnNewDocument(fmt.Sprintf(webdriverEvasion, languages))
+
+	return err
+}
+
+// languages returns the languages of the Accept-Language header, without
+// their weights.
+func (f Fingerprint) languages() []string {
+	var languages []string
+
+	for _, language := range strings.Split(f.AcceptLanguage, ",") {
+		language, _, _ = strings.Cut(language, ";")
+		if language = strings.TrimSpace(language); language != "" {
+			languages = append(languages, language)
+		}
+	}
+
+	return languages
+}
+
+// FingerprintPool rotates the fingerprints of the browser sessions.
+type FingerprintPool struct {
+	mu           sync.Mutex
+	fingerprints []Fingerprint
+	next         int
+}
+
+// NewFingerprintPool validates fingerprints; without any it uses the default
+// desktop fingerprints.
+func NewFingerprintPool(fingerprints []Fingerprint) (*FingerprintPool, error) {
+	if len(fingerprints) == 0 {
+		fingerprints = defaultFingerprints
+	}
+
+	pool := &FingerprintPool{
+		fingerprints: make([]Fingerprint, len(fingerprints)),
+	}
+
+	copy(pool.fingerprints, fingerprints)
+
+	var errs []error
+	for i := range pool.fingerprints {
+		if err := pool.fingerprints[i].validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return pool, nil
+}
+
+// LoadFingerprintPool reads the fingerprints from a YAML list in path, or uses
+// the default ones when path is empty.
+func LoadFingerprintPool(path string) (*FingerprintPool, error) {
+	if path == "" {
+		return NewFingerprintPool(nil)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fingerprints []Fingerprint
+	if err := yaml.Unmarshal(data, &fingerprints); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if len(fingerprints) == 0 {
+		return nil, fmt.Errorf("%s: no fingerprints", path)
+	}
+
+	return NewFingerprintPool(fingerprints)
+}
+
+// Next returns the fingerprint for a new browser session, round robin.
+func (p *FingerprintPool) Next() Fingerprint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fingerprint := p.fingerprints[p.next]
+	p.next = (p.next + 1) % len(p.fingerprints)
+
+	return fingerprint
+}
+
+// WithFingerprints rotates the fingerprints of pool across browser sessions.
+func WithFingerprints(pool *FingerprintPool) Option {
+	return func(o *scrapperOptions) {
+		o.fingerprints = pool
+	}
+}
+
+// nextFingerprint returns the fingerprint for a new browser session. Without
+// a pool every session uses the first default fingerprint.
+func (o scrapperOptions) nextFingerprint() Fingerprint {
+	if o.fingerprints == nil {
+		fingerprint := defaultFingerprints[0]
+		fingerprint.validate() //nolint:errcheck
+
+		return fingerprint
+	}
+
+	return o.fingerprints.Next()
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadFingerprintPool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fingerprints.yaml")
+
+	data := []byte(`
+- name: desktop
+  user_agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64)
+  width: 1280
+  height: 720
+- name: english
+  user_agent: Mozilla/5.0 (X11; Linux x86_64)
+  width: 1024
+  height: 768
+  accept_language: en-US,en;q=0.8
+`)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := LoadFingerprintPool(path)
+	if err != nil {
+		t.Fatalf("LoadFingerprintPool() error = %v", err)
+	}
+
+	first := pool.Next()
+	if first.Name != "desktop" || first.Locale != defaultLocale || first.Timezone != defaultTimezone {
+		t.Errorf("first fingerprint = %+v, want desktop with the Peruvian defaults", first)
+	}
+
+	if got, want := pool.Next().languages(), []string{"en-US", "en"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("languages() = %v, want %v", got, want)
+	}
+
+	if got := pool.Next().Name; got != "desktop" {
+		t.Errorf("third Next() = %q, want the pool to wrap around", got)
+	}
+}
+
+func TestNewFingerprintPoolRejectsIncompleteFingerprints(t *testing.T) {
+	if _, err := NewFingerprintPool([]Fingerprint{{Name: "broken"}}); err == nil {
+		t.Fatal("NewFingerprintPool() error = nil, want missing user agent and viewport")
+	}
+}
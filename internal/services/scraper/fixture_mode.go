@@ -0,0 +1,158 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/fixtures"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Fixture modes. In record mode every fetched page is saved to the fixture
+// directory, keyed by its URL, along with the XHR and fetch responses it
+// triggered. In replay mode pages and responses are served from that
+// directory and every other request fails, so production searches can be
+// reproduced offline.
+const (
+	FixtureModeLive   = ""
+	FixtureModeRecord = "record"
+	FixtureModeReplay = "replay"
+)
+
+// WithFixtures sets the fixture mode and directory of a scrapper.
+func WithFixtures(mode string, dir string) Option {
+	return func(o *scrapperOptions) {
+		o.fixtureMode = mode
+		o.fixtureDir = dir
+	}
+}
+
+// IsFixtureMode reports whether mode is a known fixture mode.
+func IsFixtureMode(mode string) bool {
+	switch mode {
+	case FixtureModeLive, FixtureModeRecord, FixtureModeReplay:
+		return true
+	}
+
+	return false
+}
+
+// recordPage saves the rendered HTML of page as the fixture of pageURL.
+func (o scrapperOptions) recordPage(page *rod.Page, pageURL string) {
+	if o.fixtureMode != FixtureModeRecord {
+		return
+	}
+
+	html, err := page.HTML()
+	if err != nil {
+		log.Println("Recording page failed:", err)
+		return
+	}
+
+	o.recordBody(fixtures.Key(pageURL), []byte(html))
+}
+
+func (o scrapperOptions) recordBody(name string, body []byte) {
+	if o.fixtureMode != FixtureModeRecord {
+		return
+	}
+
+	if err := fixtures.Write(o.fixtureDir, name, body); err != nil {
+		log.Println("Recording fixture failed:", err)
+		return
+	}
+
+	log.Println("Recorded fixture", name)
+}
+
+func (o scrapperOptions) recordResponses(page *rod.Page) func() {
+	if err := (proto.NetworkEnable{}).Call(page); err != nil {
+		log.Println("Recording responses failed:", err)
+		return func() {}
+	}
+
+	events, cancel := page.WithCancel()
+
+	// response URLs by request, until their body finished loading
+	responses := make(map[proto.NetworkRequestID]string)
+
+	go events.EachEvent(func(e *proto.NetworkResponseReceived) {
+		if e.Type == proto.NetworkResourceTypeXHR || e.Type == proto.NetworkResourceTypeFetch {
+			responses[e.RequestID] = e.Response.URL
+		}
+	}, func(e *proto.NetworkLoadingFinished) {
+		responseURL, ok := responses[e.RequestID]
+		if !ok {
+			return
+		}
+		delete(responses, e.RequestID)
+
+		go func() {
+			body, err := proto.NetworkGetResponseBody{RequestID: e.RequestID}.Call(events)
+			if err != nil {
+				return
+			}
+
+			data := []byte(body.Body)
+			if body.Base64Encoded {
+				data, err = base64.StdEncoding.DecodeString(body.Body)
+				if err != nil {
+					return
+				}
+			}
+
+			o.recordBody(fixtures.ResponseKey(responseURL), data)
+		}()
+	})()
+
+	return cancel
+}
+
+// replayRequest answers a hijacked request from the fixture directory, or
+// fails it when it was not recorded.
+func (o scrapperOptions) replayRequest(ctx *rod.Hijack) {
+	requestURL := ctx.Request.URL().String()
+
+	name := fixtures.ResponseKey(requestURL)
+	if ctx.Request.IsNavigation() {
+		name = fixtures.Key(requestURL)
+	}
+
+	body, err := fixtures.Read(o.fixtureDir, name)
+	if err != nil {
+		ctx.Response.Fail(proto.NetworkErrorReasonInternetDisconnected)
+		return
+	}
+
+	ctx.Response.SetHeader("Content-Type", fixtureContentType(body, ctx.Request.IsNavigation()))
+	ctx.Response.SetBody(body)
+}
+
+// readFixture returns the fixture recorded under name in replay mode.
+func (o scrapperOptions) readFixture(name string) ([]byte, bool, error) {
+	if o.fixtureMode != FixtureModeReplay {
+		return nil, false, nil
+	}
+
+	body, err := fixtures.Read(o.fixtureDir, name)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return body, true, nil
+}
+
+func fixtureContentType(body []byte, navigation bool) string {
+	if navigation {
+		return "text/html; charset=utf-8"
+	}
+
+	if json.Valid(body) {
+		return "application/json"
+	}
+
+	return http.DetectContentType(body)
+}
@@ -0,0 +1,415 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/fixtures"
+)
+
+const (
+	httpScrapperTimeout = 20 * time.Second
+	maxProxyAttempts    = 3
+	httpUserAgent       = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+)
+
+// HTTPScrapper scrapes server-side rendered pages described by a site profile
+// with a plain HTTP request and an HTML parser, without launching a browser.
+// Only the query and next_link pagination strategies are followed.
+type HTTPScrapper struct {
+	profiles *ProfileStore
+	name     string
+	options  scrapperOptions
+	client   *http.Client
+}
+
+func NewHTTPScrapper(profiles *ProfileStore, name string, opts ...Option) *HTTPScrapper {
+	return &HTTPScrapper{
+		profiles: profiles,
+		name:     name,
+		options:  newScrapperOptions(opts),
+		client:   &http.Client{Timeout: httpScrapperTimeout},
+	}
+}
+
+func (s *HTTPScrapper) FindByFilter(ctx context.Context, filter dtos.AutoFilter) (autos []*dtos.AutoFilterResponse, err error) {
+	profile, err := s.profiles.Profile(s.name)
+	if err != nil {
+		return nil, err
+	}
+
+	run := s.options.startRun(profile.Name, BackendHTTP, filter)
+	e := newExtraction()
+
+	defer func() {
+		err = s.options.finishRun(run, e, len(autos), err)
+	}()
+
+	searchURL, err := profile.BuildURL(filter, s.options.baseURL, s.options.catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	run.URL = searchURL
+
+	release, err := s.options.acquirePage(ctx, searchURL, profile.Politeness)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// with per session rotation every page of the search uses the same proxy
+	var session *Proxy
+	if s.options.proxies != nil && s.options.proxies.Rotation() == ProxyRotationSession {
+		session, err = s.options.nextProxy()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	autos = make([]*dtos.AutoFilterResponse, 0)
+	seen := make(map[string]bool)
+	pageURL := searchURL
+
+	for index := 0; index < profile.Pagination.Pages(); index++ {
+		if index > 0 {
+			if err := s.options.navigateTo(ctx, pageURL, profile.Politeness); err != nil {
+				break
+			}
+		}
+
+		run.Pages++
+
+		document, err := s.fetch(ctx, run, profile, pageURL, session)
+		if err != nil {
+			if index == 0 {
+				return nil, err
+			}
+
+			break
+		}
+
+		added := 0
+
+		// listings embedded as JSON survive redesigns better than the markup
+		found := profile.Data.staticListings(document, pageURL, e)
+		if len(found) == 0 {
+			found = s.extractItems(document, profile, pageURL, e)
+		}
+
+		for _, auto := range found {
+			if seen[auto.URL] {
+				continue
+			}
+
+			seen[auto.URL] = true
+			autos = append(autos, auto)
+			added++
+		}
+
+		if added == 0 {
+			if index == 0 {
+				// block pages are answered with 200 by some sites
+				if html, err := document.Html(); err == nil {
+					if err := profile.blocked(http.StatusOK, html); err != nil {
+						return nil, err
+					}
+				}
+			}
+
+			break
+		}
+
+		nextURL, ok := s.nextPage(document, profile, searchURL, pageURL, index)
+		if !ok {
+			break
+		}
+
+		pageURL = nextURL
+	}
+
+	// a canceled search ends between pages, without its later results
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.options.recordQuality(profile, e)
+
+	return autos, nil
+}
+
+func (s *HTTPScrapper) fetch(ctx context.Context, run *scrapeRun, profile *SiteProfile, pageURL string, session *Proxy) (*goquery.Document, error) {
+	body, replayed, err := s.options.readFixture(fixtures.Key(pageURL))
+	if err != nil {
+		return nil, err
+	}
+
+	if !replayed {
+		body, err = s.download(ctx, run, profile, pageURL, session)
+		if err != nil {
+			return nil, err
+		}
+
+		s.options.recordBody(fixtures.Key(pageURL), body)
+	}
+
+	run.bytes.Add(int64(len(body)))
+
+	return goquery.NewDocumentFromReader(bytes.NewReader(body))
+}
+
+// download gets pageURL through the session proxy or, with per request
+// rotation, through the next proxies of the pool until one succeeds.
+func (s *HTTPScrapper) download(ctx context.Context, run *scrapeRun, profile *SiteProfile, pageURL string, session *Proxy) ([]byte, error) {
+	attempts := 1
+	if session == nil && s.options.proxies != nil {
+		attempts = min(s.options.proxies.Size(), maxProxyAttempts)
+	}
+
+	var err error
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		proxy := session
+		if proxy == nil {
+			proxy, err = s.options.nextProxy()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		run.useProxy(proxy)
+
+		var body []byte
+		body, err = s.get(ctx, profile, pageURL, proxy)
+		s.options.reportProxy(proxy, err)
+
+		if err == nil {
+			return body, nil
+		}
+
+		if proxy != nil {
+			log.Println("Request through proxy", proxy.URL.Redacted(), "failed:", err)
+		}
+	}
+
+	return nil, err
+}
+
+func (s *HTTPScrapper) get(ctx context.Context, profile *SiteProfile, pageURL string, proxy *Proxy) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", httpUserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("Accept-Language", "es-PE,es;q=0.9")
+
+	client := s.client
+	if proxy != nil {
+		client = &http.Client{Timeout: s.client.Timeout, Transport: proxy.Transport}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if err := profile.blocked(resp.StatusCode, string(body)); err != nil {
+			return nil, err
+		}
+
+		return nil, &statusError{code: resp.StatusCode, url: pageURL}
+	}
+
+	return body, nil
+}
+
+// statusError is returned when a page answers with a status other than 200.
+type statusError struct {
+	code int
+	url  string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s fetching %s", e.code, http.StatusText(e.code), e.url)
+}
+
+// isBlockedStatus reports whether err is a status telling the client it is
+// not welcome, as opposed to a transient failure.
+func isBlockedStatus(err error) bool {
+	var statusErr *statusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+
+	switch statusErr.code {
+	case http.StatusForbidden, http.StatusProxyAuthRequired, http.StatusTooManyRequests:
+		return true
+	}
+
+	return false
+}
+
+// extractItems maps every item in the list container to a response, with the
+// same rules as the browser based ProfileScrapper.
+func (s *HTTPScrapper) extractItems(document *goquery.Document, profile *SiteProfile, pageURL string, e *extraction) []*dtos.AutoFilterResponse {
+	autos := make([]*dtos.AutoFilterResponse, 0)
+
+	document.Find(profile.ListContainer).First().Find(profile.Item).Each(func(_ int, item *goquery.Selection) {
+		e.item()
+
+		url, err := s.field(item, profile, FieldURL, pageURL)
+		if !e.require(FieldURL, err) {
+			return
+		}
+
+		title, err := s.field(item, profile, FieldTitle, pageURL)
+		if !e.require(FieldTitle, err) {
+			return
+		}
+
+		textPrice, err := s.field(item, profile, FieldPrice, pageURL)
+
+		var price float64
+		if err == nil {
+			price, err = strconv.ParseFloat(textPrice, 64)
+		}
+
+		if !e.require(FieldPrice, err) {
+			return
+		}
+
+		imageURL, err := s.field(item, profile, FieldImage, pageURL)
+		e.optional(FieldImage, err)
+		images := listingImages(imageURL, findStaticFieldValues(item, profile.Fields[FieldImages]), profile.Fields[FieldImages], pageURL)
+
+		if imageURL == "" && len(images) > 0 {
+			imageURL = images[0]
+		}
+
+		autos = append(autos, &dtos.AutoFilterResponse{
+			Title:     title,
+			Price:     price,
+			URL:       url,
+			ImageURL:  imageURL,
+			ImageURLs: images,
+		})
+		e.done()
+	})
+
+	return autos
+}
+
+func (s *HTTPScrapper) field(item *goquery.Selection, profile *SiteProfile, name string, pageURL string) (string, error) {
+	field := profile.Fields[name]
+
+	value, err := findStaticField(item, name, field)
+	if err != nil {
+		return "", err
+	}
+
+	return field.Apply(value, pageURL)
+}
+
+func (s *HTTPScrapper) nextPage(document *goquery.Document, profile *SiteProfile, searchURL string, pageURL string, index int) (string, bool) {
+	if index+1 >= profile.Pagination.Pages() {
+		return "", false
+	}
+
+	switch profile.Pagination.Strategy {
+	case PaginationQuery:
+		nextURL, err := profile.Pagination.PageURL(searchURL, index+1)
+		if err != nil {
+			return "", false
+		}
+
+		return nextURL, true
+	case PaginationNextLink:
+		href, ok := document.Find(profile.Pagination.NextSelector).First().Attr("href")
+		if !ok || href == "" {
+			return "", false
+		}
+
+		nextURL, err := profile.AbsoluteURL(href, pageURL)
+		if err != nil {
+			return "", false
+		}
+
+		return nextURL, true
+	}
+
+	return "", false
+}
+
+// findStaticField is the parsed HTML counterpart of findField.
+func findStaticField(item *goquery.Selection, name string, field FieldSpec) (string, error) {
+	kind, attributes, err := field.extractor()
+	if err != nil {
+		return "", err
+	}
+
+	for _, selector := range field.Selectors {
+		element := item.Find(selector).First()
+		if element.Length() == 0 {
+			continue
+		}
+
+		values := field.values(staticValue(element, kind, attributes, field))
+		if len(values) == 0 {
+			continue
+		}
+
+		return values[0], nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+}
+
+// findStaticFieldValues is the parsed HTML counterpart of findFieldValues.
+func findStaticFieldValues(item *goquery.Selection, field FieldSpec) []string {
+	kind, attributes, err := field.extractor()
+	if err != nil {
+		return nil
+	}
+
+	var values []string
+
+	for _, selector := range field.Selectors {
+		item.Find(selector).Each(func(_ int, element *goquery.Selection) {
+			values = append(values, field.values(staticValue(element, kind, attributes, field))...)
+		})
+	}
+
+	return values
+}
+
+func staticValue(element *goquery.Selection, kind string, attributes []string, field FieldSpec) string {
+	if kind == "text" {
+		return element.Text()
+	}
+
+	for _, attribute := range attributes {
+		if value := field.attributeValue(attribute, element.AttrOr(attribute, "")); value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
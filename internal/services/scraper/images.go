@@ -0,0 +1,569 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"log"
+	"maps"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	// decoders of the listing images
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	imageQueueSize   = 1000
+	imageWorkers     = 4
+	imageTimeout     = 30 * time.Second
+	maxImageSize     = 10 << 20
+	maxImagePixels   = 50_000_000
+	imageIndexSize   = 100_000
+	thumbnailWidth   = 320
+	thumbnailQuality = 80
+	imageCacheMaxAge = "public, max-age=31536000, immutable"
+)
+
+// Blob keys of a cached image, under its ID.
+const (
+	imageOriginal  = "original"
+	imageThumbnail = "thumb.jpg"
+	imageMeta      = "meta.json"
+)
+
+// imagePoliteness paces the downloads from every image host. Images are
+// fetched for the listings already shown, not crawled, so robots.txt is not
+// checked.
+var imagePoliteness = PolitenessPolicy{
+	RequestsPerMinute:  120,
+	Burst:              4,
+	MaxConcurrentPages: imageWorkers,
+	IgnoreRobots:       true,
+}
+
+// imageTypes are the content types of the image formats the cache decodes,
+// the only ones it stores and serves.
+var imageTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+}
+
+// ErrBlobNotFound is returned by a BlobStore for keys it does not hold.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore keeps the cached images by key.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// DirBlobStore keeps every blob in a file of a directory, at its key.
+type DirBlobStore struct {
+	dir string
+}
+
+func NewDirBlobStore(dir string) *DirBlobStore {
+	return &DirBlobStore{dir: dir}
+}
+
+func (s *DirBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// readers never see a partly written blob
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (s *DirBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+
+	return data, err
+}
+
+// ImageInfo describes a cached image. Hash is its 64 bit difference hash in
+// hex: images that look alike, e.g. the same photo resized or recompressed,
+// have hashes a few bits apart. Downloads the cache cannot decode as an
+// image are never stored.
+type ImageInfo struct {
+	ID          string    `json:"id"`
+	SourceURL   string    `json:"source_url"`
+	ContentType string    `json:"content_type"`
+	Size        int       `json:"size"`
+	Width       int       `json:"width,omitempty"`
+	Height      int       `json:"height,omitempty"`
+	Hash        string    `json:"hash,omitempty"`
+	CachedAt    time.Time `json:"cached_at"`
+}
+
+// ImageID returns the stable ID of the image at sourceURL.
+func ImageID(sourceURL string) string {
+	sum := sha256.Sum256([]byte(sourceURL))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ImageCache downloads the images of the listings in the background and
+// serves them, with their thumbnails, from its own URLs, so clients never
+// depend on the hotlinking rules or the expiring URLs of the sources.
+type ImageCache struct {
+	store   BlobStore
+	baseURL string
+	options scrapperOptions
+	client  *http.Client
+	queue   chan imageJob
+
+	mu sync.Mutex
+	// sources are the source URLs of the images not cached yet, by ID; they
+	// and the IDs of the cached images only keep the last ones used
+	sources *lru[string, string]
+	pending map[string]bool
+	cached  *lru[string, bool]
+}
+
+// imageJob is an image to download, with the listing it was found in.
+type imageJob struct {
+	id      string
+	source  string
+	listing string
+}
+
+// NewImageCache keeps the images in store and serves them at baseURL, the
+// URL the ImageCache handler is mounted at, e.g. "/images/".
+func NewImageCache(store BlobStore, baseURL string, opts ...Option) *ImageCache {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+
+	return &ImageCache{
+		store:   store,
+		baseURL: baseURL,
+		options: newScrapperOptions(opts),
+		client:  &http.Client{Timeout: imageTimeout},
+		queue:   make(chan imageJob, imageQueueSize),
+		sources: newLRU[string, string](imageIndexSize),
+		pending: make(map[string]bool),
+		cached:  newLRU[string, bool](imageIndexSize),
+	}
+}
+
+// Wrap returns scrapper, with the listings it finds carrying the URLs of
+// their cached images.
+func (c *ImageCache) Wrap(scrapper AutoScrapper) AutoScrapper {
+	return &cachedImagesScrapper{
+		cache:    c,
+		scrapper: scrapper,
+	}
+}
+
+// cachedImagesScrapper sets the cached image URLs of the listings of a
+// scrapper and queues their download.
+type cachedImagesScrapper struct {
+	cache    *ImageCache
+	scrapper AutoScrapper
+}
+
+func (s *cachedImagesScrapper) FindByFilter(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+	autos, err := s.scrapper.FindByFilter(ctx, filter)
+
+	for _, auto := range autos {
+		s.cache.rewrite(auto)
+	}
+
+	return autos, err
+}
+
+// rewrite sets the cached image URLs of auto and queues their download.
+func (c *ImageCache) rewrite(auto *dtos.AutoFilterResponse) {
+	auto.CachedImageURL = ""
+	auto.CachedImageURLs = nil
+	auto.ThumbnailURL = ""
+
+	for _, source := range auto.ImageURLs {
+		auto.CachedImageURLs = append(auto.CachedImageURLs, c.baseURL+c.enqueue(source, auto.URL))
+	}
+
+	if auto.ImageURL == "" {
+		return
+	}
+
+	id := c.enqueue(auto.ImageURL, auto.URL)
+	auto.CachedImageURL = c.baseURL + id
+	auto.ThumbnailURL = c.baseURL + id + "/thumb"
+}
+
+// enqueue queues the download of the image at source, unless it is cached or
+// already queued, and returns its ID. Images are dropped while the queue is
+// full; the next search finding them queues them again.
+func (c *ImageCache) enqueue(source string, listing string) string {
+	id := ImageID(source)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, cached := c.cached.Get(id); cached || c.pending[id] {
+		return id
+	}
+
+	c.sources.Put(id, source)
+
+	select {
+	case c.queue <- imageJob{id: id, source: source, listing: listing}:
+		c.pending[id] = true
+	default:
+	}
+
+	return id
+}
+
+// Run downloads the queued images until ctx is done.
+func (c *ImageCache) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for range imageWorkers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-c.queue:
+					err := c.cache(ctx, job)
+					if err != nil {
+						log.Println("caching image", job.source, "failed:", err)
+					}
+
+					c.done(job.id, err == nil)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (c *ImageCache) done(id string, cached bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pending, id)
+
+	if cached {
+		c.cached.Put(id, true)
+		c.sources.Remove(id)
+	}
+}
+
+// cache downloads the image of job and stores it with its thumbnail and its
+// info, the info last as it marks the image cached.
+func (c *ImageCache) cache(ctx context.Context, job imageJob) error {
+	if _, err := c.store.Get(ctx, job.id+"/"+imageMeta); err == nil {
+		return nil
+	}
+
+	data, err := c.download(ctx, job)
+	if err != nil {
+		return err
+	}
+
+	// only images are stored, the cache serves them from our own origin;
+	// a small file may still decode to a bitmap too large for memory
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("not a decodable image: %w", err)
+	}
+
+	if config.Width*config.Height > maxImagePixels {
+		return fmt.Errorf("image of %dx%d pixels larger than %d pixels", config.Width, config.Height, maxImagePixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("not a decodable image: %w", err)
+	}
+
+	thumbnail, err := encodeThumbnail(img)
+	if err != nil {
+		return err
+	}
+
+	bounds := img.Bounds()
+	info := ImageInfo{
+		ID:          job.id,
+		SourceURL:   job.source,
+		ContentType: imageTypes[format],
+		Size:        len(data),
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+		Hash:        encodeHash(perceptualHash(img)),
+		CachedAt:    time.Now(),
+	}
+
+	if err := c.store.Put(ctx, job.id+"/"+imageOriginal, data); err != nil {
+		return err
+	}
+
+	if err := c.store.Put(ctx, job.id+"/"+imageThumbnail, thumbnail); err != nil {
+		return err
+	}
+
+	meta, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	return c.store.Put(ctx, job.id+"/"+imageMeta, meta)
+}
+
+// download gets the image of job, from the listing page as far as the image
+// host can tell.
+func (c *ImageCache) download(ctx context.Context, job imageJob) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, job.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "image/avif,image/webp,image/*,*/*;q=0.8")
+
+	// hotlink protection lets through the requests coming from the site
+	if listing, err := url.Parse(job.listing); err == nil && listing.Host != "" {
+		req.Header.Set("Referer", listing.Scheme+"://"+listing.Host+"/")
+	}
+
+	release, err := c.options.acquirePage(ctx, job.source, imagePoliteness)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	resp, err := c.options.send(c.client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{code: resp.StatusCode, url: job.source}
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("%s is served as %q, not an image", job.source, contentType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) > maxImageSize {
+		return nil, fmt.Errorf("image larger than %d bytes", maxImageSize)
+	}
+
+	return data, nil
+}
+
+// ServeHTTP serves the cached images at "<id>", their thumbnails at
+// "<id>/thumb" and their info at "<id>/info", relative to the base URL.
+// Images not cached yet redirect to their source while they download.
+func (c *ImageCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, variant, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+	if _, err := hex.DecodeString(id); err != nil || len(id) != 32 {
+		http.NotFound(w, r)
+		return
+	}
+
+	key := id + "/" + imageOriginal
+
+	switch variant {
+	case "":
+	case "thumb":
+		key = id + "/" + imageThumbnail
+	case "info":
+		key = id + "/" + imageMeta
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := c.store.Get(r.Context(), key)
+
+	switch {
+	case errors.Is(err, ErrBlobNotFound) && variant == "info":
+		http.NotFound(w, r)
+		return
+	case errors.Is(err, ErrBlobNotFound):
+		c.redirectToSource(w, r, id)
+		return
+	case err != nil:
+		log.Println("reading cached image", id, "failed:", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	if variant == "info" {
+		contentType = "application/json"
+	} else if !slices.Contains(slices.Collect(maps.Values(imageTypes)), contentType) {
+		// never serve anything but images from our origin
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", imageCacheMaxAge)
+	w.Write(data) //nolint:errcheck
+}
+
+// redirectToSource sends the client to the source of an image that is not
+// cached yet, queueing it again in case its download failed.
+func (c *ImageCache) redirectToSource(w http.ResponseWriter, r *http.Request, id string) {
+	c.mu.Lock()
+	source, ok := c.sources.Get(id)
+	c.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	c.enqueue(source, "")
+
+	http.Redirect(w, r, source, http.StatusFound)
+}
+
+// encodeThumbnail scales img down to the thumbnail width, as a JPEG.
+func encodeThumbnail(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > thumbnailWidth {
+		height = max(1, height*thumbnailWidth/width)
+		width = thumbnailWidth
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, downscale(img, width, height), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// perceptualHash is the difference hash of img: it is scaled down to 9x8
+// gray pixels and every bit tells whether a pixel is brighter than its right
+// neighbour.
+func perceptualHash(img image.Image) uint64 {
+	small := downscale(img, 9, 8)
+
+	var hash uint64
+
+	for y := range 8 {
+		for x := range 8 {
+			hash <<= 1
+
+			if luminance(small.At(x, y)) > luminance(small.At(x+1, y)) {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+func encodeHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// HashDistance is the number of bits two image hashes differ in, or -1 when
+// either is not a hash.
+func HashDistance(a, b string) int {
+	x, err := strconv.ParseUint(a, 16, 64)
+	if err != nil {
+		return -1
+	}
+
+	y, err := strconv.ParseUint(b, 16, 64)
+	if err != nil {
+		return -1
+	}
+
+	return bits.OnesCount64(x ^ y)
+}
+
+func luminance(c color.Color) uint32 {
+	r, g, b, _ := c.RGBA()
+	return (299*r + 587*g + 114*b) / 1000
+}
+
+// downscale scales img to width x height, every pixel averaging the pixels
+// of its box.
+func downscale(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+
+		for x := range width {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, n uint64
+
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return dst
+}
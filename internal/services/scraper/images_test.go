@@ -0,0 +1,297 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+)
+
+// scrapperFunc is an AutoScrapper returning the listings of a function, for
+// tests.
+type scrapperFunc func(filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error)
+
+func (f scrapperFunc) FindByFilter(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+	return f(filter)
+}
+
+// gradient is a test photo: a diagonal gradient with a dark square.
+func gradient(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		for x := range width {
+			c := uint8((x + y) * 255 / (width + height))
+			if x > width/4 && x < width/2 && y > height/4 && y < height/2 {
+				c /= 4
+			}
+
+			img.SetRGBA(x, y, color.RGBA{R: c, G: 255 - c, B: c / 2, A: 255})
+		}
+	}
+
+	return img
+}
+
+func TestImageCache(t *testing.T) {
+	var photo bytes.Buffer
+	if err := png.Encode(&photo, gradient(640, 480)); err != nil {
+		t.Fatal(err)
+	}
+
+	referers := make(chan string, 1)
+
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		referers <- r.Referer()
+		w.Write(photo.Bytes())
+	}))
+	t.Cleanup(cdn.Close)
+
+	cache := NewImageCache(NewDirBlobStore(t.TempDir()), "/images")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	scrapper := cache.Wrap(scrapperFunc(func(filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+		return []*dtos.AutoFilterResponse{{
+			Title:     "Toyota Yaris 2019",
+			URL:       "https://autos.test/yaris",
+			ImageURL:  cdn.URL + "/yaris.png",
+			ImageURLs: []string{cdn.URL + "/yaris.png"},
+		}}, nil
+	}))
+
+	autos, err := scrapper.FindByFilter(context.Background(), dtos.AutoFilter{})
+	if err != nil {
+		t.Fatalf("FindByFilter() error = %v", err)
+	}
+
+	id := ImageID(cdn.URL + "/yaris.png")
+	auto := autos[0]
+
+	if auto.CachedImageURL != "/images/"+id || auto.ThumbnailURL != "/images/"+id+"/thumb" || len(auto.CachedImageURLs) != 1 {
+		t.Fatalf("cached URLs = %q, %q, %v, want the /images/ URLs of %s", auto.CachedImageURL, auto.ThumbnailURL, auto.CachedImageURLs, id)
+	}
+
+	// not downloaded yet, the client is sent to the source
+	rec := httptest.NewRecorder()
+	cache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+id, nil))
+
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != auto.ImageURL {
+		t.Fatalf("GET before download = %d %s, want a redirect to the source", rec.Code, rec.Header().Get("Location"))
+	}
+
+	go cache.Run(ctx)
+
+	if referer := <-referers; referer != "https://autos.test/" {
+		t.Errorf("download Referer = %q, want the listing site", referer)
+	}
+
+	var info ImageInfo
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		rec = httptest.NewRecorder()
+		cache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+id+"/info", nil))
+
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+				t.Fatal(err)
+			}
+
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("image not cached, GET info = %d", rec.Code)
+		}
+	}
+
+	if info.Width != 640 || info.Height != 480 || info.ContentType != "image/png" || len(info.Hash) != 16 {
+		t.Errorf("info = %+v, want a 640x480 PNG with its hash", info)
+	}
+
+	rec = httptest.NewRecorder()
+	cache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+id, nil))
+
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), photo.Bytes()) {
+		t.Errorf("GET original = %d, want the source image", rec.Code)
+	}
+
+	if rec.Header().Get("Content-Type") != "image/png" || rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("GET original headers = %v, want a PNG never sniffed", rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	cache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+id+"/thumb", nil))
+
+	thumbnail, err := jpeg.Decode(rec.Body)
+	if err != nil {
+		t.Fatalf("GET thumb is not a JPEG: %v", err)
+	}
+
+	if bounds := thumbnail.Bounds(); bounds.Dx() != 320 || bounds.Dy() != 240 {
+		t.Errorf("thumbnail is %dx%d, want 320x240", bounds.Dx(), bounds.Dy())
+	}
+
+	rec = httptest.NewRecorder()
+	cache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/not-an-id", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET unknown image = %d, want 404", rec.Code)
+	}
+}
+
+func TestPerceptualHash(t *testing.T) {
+	original := gradient(640, 480)
+
+	// the same photo, smaller and recompressed
+	var recompressed bytes.Buffer
+	if err := jpeg.Encode(&recompressed, downscale(original, 200, 150), &jpeg.Options{Quality: 50}); err != nil {
+		t.Fatal(err)
+	}
+
+	resized, err := jpeg.Decode(&recompressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := func(img image.Image) string {
+		return encodeHash(perceptualHash(img))
+	}
+
+	if d := HashDistance(hash(original), hash(resized)); d < 0 || d > 6 {
+		t.Errorf("HashDistance(original, recompressed) = %d, want at most 6", d)
+	}
+
+	// a different photo
+	other := image.NewRGBA(image.Rect(0, 0, 640, 480))
+	for y := range 480 {
+		for x := range 640 {
+			other.SetRGBA(x, y, color.RGBA{R: uint8(x * y), G: uint8(x), B: uint8(y), A: 255})
+		}
+	}
+
+	if d := HashDistance(hash(original), hash(other)); d < 16 {
+		t.Errorf("HashDistance(original, other) = %d, want at least 16", d)
+	}
+}
+
+func TestImageCacheRejectsHugeImages(t *testing.T) {
+	// a GIF header announcing a 65535x65535 screen
+	bomb := []byte("GIF89a\xff\xff\xff\xff\x00\x00\x00")
+
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bomb)
+	}))
+	t.Cleanup(cdn.Close)
+
+	store := NewDirBlobStore(t.TempDir())
+	cache := NewImageCache(store, "/images/")
+
+	source := cdn.URL + "/bomb.gif"
+	job := imageJob{id: ImageID(source), source: source}
+
+	if err := cache.cache(context.Background(), job); err == nil {
+		t.Fatal("cache() of a 65535x65535 image error = nil")
+	}
+
+	if _, err := store.Get(context.Background(), job.id+"/"+imageOriginal); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("original of the rejected image error = %v, want %v", err, ErrBlobNotFound)
+	}
+}
+
+func TestImageCacheRejectsNonImages(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "html", contentType: "text/html", body: "<html><script>alert(1)</script></html>"},
+		{name: "svg", contentType: "image/svg+xml", body: `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`},
+		{name: "html as png", contentType: "image/png", body: "<html><script>alert(1)</script></html>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(cdn.Close)
+
+			store := NewDirBlobStore(t.TempDir())
+			cache := NewImageCache(store, "/images/")
+
+			source := cdn.URL + "/photo"
+			job := imageJob{id: ImageID(source), source: source}
+
+			if err := cache.cache(context.Background(), job); err == nil {
+				t.Fatal("cache() error = nil, want the download rejected")
+			}
+
+			if _, err := store.Get(context.Background(), job.id+"/"+imageOriginal); !errors.Is(err, ErrBlobNotFound) {
+				t.Errorf("original of the rejected download error = %v, want %v", err, ErrBlobNotFound)
+			}
+		})
+	}
+}
+
+func TestImageCacheServesOnlyImages(t *testing.T) {
+	store := NewDirBlobStore(t.TempDir())
+	cache := NewImageCache(store, "/images/")
+
+	// stored before downloads were checked
+	id := ImageID("https://cdn.test/page")
+	if err := store.Put(context.Background(), id+"/"+imageOriginal, []byte("<html><script>alert(1)</script></html>")); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	cache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+id, nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET of a cached page = %d, want 404", rec.Code)
+	}
+}
+
+func TestImageCacheDecodesWebP(t *testing.T) {
+	// a lossless 1x1 WebP
+	webp := []byte("RIFF\x1a\x00\x00\x00WEBPVP8L\x0d\x00\x00\x00\x2f\x00\x00\x00\x10\x07\x10\x11\x11\x88\x88\xfe\x07\x00")
+
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/webp")
+		w.Write(webp)
+	}))
+	t.Cleanup(cdn.Close)
+
+	store := NewDirBlobStore(t.TempDir())
+	cache := NewImageCache(store, "/images/")
+
+	source := cdn.URL + "/photo.webp"
+	job := imageJob{id: ImageID(source), source: source}
+
+	if err := cache.cache(context.Background(), job); err != nil {
+		t.Fatalf("cache() error = %v", err)
+	}
+
+	if _, err := store.Get(context.Background(), job.id+"/"+imageThumbnail); err != nil {
+		t.Errorf("thumbnail of the WebP image error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	cache.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/"+job.id, nil))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/webp" {
+		t.Errorf("GET of the WebP image = %d %s, want it served as image/webp", rec.Code, rec.Header().Get("Content-Type"))
+	}
+}
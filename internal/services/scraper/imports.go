@@ -0,0 +1,474 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/redis/go-redis/v9"
+)
+
+// MaxImportListings is the most listings a single import may push.
+const MaxImportListings = 10000
+
+const (
+	importsKey      = "listings:imported"
+	importsSeenKey  = "listings:imported:seen"
+	importsPriceKey = "listings:imported:price"
+	importsBrandKey = "listings:imported:brand:"
+	importsTimeout  = 5 * time.Second
+	minListingYear  = 1900
+
+	defaultImportsTTL         = 30 * 24 * time.Hour
+	defaultMaxImportsListings = 100_000
+
+	// backendImport is the backend of the import runs.
+	backendImport = "import"
+)
+
+// ImportStore keeps the imported listings by URL.
+type ImportStore interface {
+	// Save adds listings, replacing those with the same URL, and returns how
+	// many URLs were new.
+	Save(ctx context.Context, listings []*dtos.AutoFilterResponse) (int, error)
+	// Find returns the listings that may match filter: at least those of its
+	// brand, or without one, within its price range. Callers still match
+	// them against the filter.
+	Find(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error)
+}
+
+// RedisImportStore keeps the imported listings in the listings:imported Redis
+// hash, by URL. Sorted sets index their URLs by price, overall and by brand,
+// and by the time they were last imported, so listings not imported again
+// within the TTL expire and the oldest go once there are more than the
+// maximum.
+type RedisImportStore struct {
+	client      *redis.Client
+	ttl         time.Duration
+	maxListings int64
+}
+
+// NewRedisImportStore keeps the listings imported in the last ttl, 30 days
+// when it is not positive, and at most maxListings of them, 100000 when it
+// is not positive.
+func NewRedisImportStore(client *redis.Client, ttl time.Duration, maxListings int64) *RedisImportStore {
+	if ttl <= 0 {
+		ttl = defaultImportsTTL
+	}
+
+	if maxListings <= 0 {
+		maxListings = defaultMaxImportsListings
+	}
+
+	return &RedisImportStore{
+		client:      client,
+		ttl:         ttl,
+		maxListings: maxListings,
+	}
+}
+
+func (s *RedisImportStore) Save(ctx context.Context, listings []*dtos.AutoFilterResponse) (int, error) {
+	if len(listings) == 0 {
+		return 0, nil
+	}
+
+	urls := make([]string, 0, len(listings))
+	for _, listing := range listings {
+		urls = append(urls, listing.URL)
+	}
+
+	previous, err := s.listings(ctx, urls)
+	if err != nil {
+		return 0, err
+	}
+
+	now := float64(time.Now().Unix())
+	added := 0
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, listing := range listings {
+			data, err := json.Marshal(listing)
+			if err != nil {
+				return err
+			}
+
+			if old, ok := previous[listing.URL]; !ok {
+				added++
+			} else if importBrandKey(old.Brand) != importBrandKey(listing.Brand) {
+				pipe.ZRem(ctx, importBrandKey(old.Brand), listing.URL)
+			}
+
+			pipe.HSet(ctx, importsKey, listing.URL, string(data))
+			pipe.ZAdd(ctx, importsSeenKey, redis.Z{Score: now, Member: listing.URL})
+			pipe.ZAdd(ctx, importsPriceKey, redis.Z{Score: listing.Price, Member: listing.URL})
+			pipe.ZAdd(ctx, importBrandKey(listing.Brand), redis.Z{Score: listing.Price, Member: listing.URL})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return added, s.prune(ctx)
+}
+
+func (s *RedisImportStore) Find(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+	if err := s.prune(ctx); err != nil {
+		return nil, err
+	}
+
+	keys := []string{importsPriceKey}
+
+	// listings without brand may still name it in their title
+	if brand := strings.TrimSpace(filter.Brand); brand != "" {
+		keys = []string{importBrandKey(brand), importBrandKey("")}
+	}
+
+	prices := &redis.ZRangeBy{
+		Min: importPriceBound(filter.MinPrice, "-inf"),
+		Max: importPriceBound(filter.MaxPrice, "+inf"),
+	}
+
+	var urls []string
+
+	for _, key := range keys {
+		found, err := s.client.ZRangeByScore(ctx, key, prices).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		urls = append(urls, found...)
+	}
+
+	found, err := s.listings(ctx, urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// cheapest first, as indexed
+	listings := make([]*dtos.AutoFilterResponse, 0, len(found))
+	for _, listingURL := range urls {
+		if listing, ok := found[listingURL]; ok {
+			listings = append(listings, listing)
+			delete(found, listingURL)
+		}
+	}
+
+	return listings, nil
+}
+
+// listings reads the stored listings of urls, by URL.
+func (s *RedisImportStore) listings(ctx context.Context, urls []string) (map[string]*dtos.AutoFilterResponse, error) {
+	listings := make(map[string]*dtos.AutoFilterResponse, len(urls))
+	if len(urls) == 0 {
+		return listings, nil
+	}
+
+	values, err := s.client.HMGet(ctx, importsKey, urls...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var listing dtos.AutoFilterResponse
+		if err := json.Unmarshal([]byte(data), &listing); err != nil {
+			continue
+		}
+
+		listings[urls[i]] = &listing
+	}
+
+	return listings, nil
+}
+
+// prune removes the listings not imported within the TTL and the oldest
+// beyond the maximum.
+func (s *RedisImportStore) prune(ctx context.Context) error {
+	cutoff := strconv.FormatInt(time.Now().Add(-s.ttl).Unix(), 10)
+
+	urls, err := s.client.ZRangeByScore(ctx, importsSeenKey, &redis.ZRangeBy{Min: "-inf", Max: "(" + cutoff}).Result()
+	if err != nil {
+		return err
+	}
+
+	count, err := s.client.ZCard(ctx, importsSeenKey).Result()
+	if err != nil {
+		return err
+	}
+
+	if over := count - int64(len(urls)) - s.maxListings; over > 0 {
+		oldest, err := s.client.ZRange(ctx, importsSeenKey, int64(len(urls)), int64(len(urls))+over-1).Result()
+		if err != nil {
+			return err
+		}
+
+		urls = append(urls, oldest...)
+	}
+
+	if len(urls) == 0 {
+		return nil
+	}
+
+	listings, err := s.listings(ctx, urls)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, listingURL := range urls {
+			if listing, ok := listings[listingURL]; ok {
+				pipe.ZRem(ctx, importBrandKey(listing.Brand), listingURL)
+			}
+
+			pipe.HDel(ctx, importsKey, listingURL)
+			pipe.ZRem(ctx, importsSeenKey, listingURL)
+			pipe.ZRem(ctx, importsPriceKey, listingURL)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+// importBrandKey is the key of the price index of the listings of brand,
+// matched ignoring case like the filter; listings without brand share the
+// empty one.
+func importBrandKey(brand string) string {
+	return importsBrandKey + strings.ToLower(strings.TrimSpace(brand))
+}
+
+// importPriceBound formats a price filter bound as a sorted set score, open
+// bounds being unbounded.
+func importPriceBound(bound *float64, unbounded string) string {
+	if value := floatBound(bound); value != "" {
+		return value
+	}
+
+	return unbounded
+}
+
+// ImportRejection is an imported listing that failed validation, by its
+// position in the import.
+type ImportRejection struct {
+	Index int
+	URL   string
+	// Reason is the skip reason of the listing, e.g. "price_missing".
+	Reason string
+}
+
+// ImportResult counts what an import did with the listings it received.
+type ImportResult struct {
+	Received int
+	// Imported counts the URLs that were new and Updated those that replaced
+	// a previous import.
+	Imported   int
+	Updated    int
+	Duplicates int
+	Rejected   []ImportRejection
+}
+
+// ImportSource is the AutoScrapper of the listings pushed by other tools.
+// Imports are validated like scraped listings, deduplicated by URL and
+// audited as runs of their own source, so they are searched and measured
+// next to the scraped ones.
+type ImportSource struct {
+	name    string
+	store   ImportStore
+	options scrapperOptions
+}
+
+// NewImportSource returns the source, named name, of the listings kept in
+// store.
+func NewImportSource(name string, store ImportStore, opts ...Option) *ImportSource {
+	return &ImportSource{
+		name:    name,
+		store:   store,
+		options: newScrapperOptions(opts),
+	}
+}
+
+func (s *ImportSource) FindByFilter(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, importsTimeout)
+	defer cancel()
+
+	listings, err := s.store.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	autos := make([]*dtos.AutoFilterResponse, 0)
+
+	for _, listing := range listings {
+		if listingMatches(listing, filter) {
+			autos = append(autos, listing)
+		}
+	}
+
+	return autos, nil
+}
+
+// Import validates and saves listings. Invalid listings are rejected and
+// repeated URLs keep their first listing; only a failing store is an error.
+func (s *ImportSource) Import(ctx context.Context, listings []*dtos.AutoFilterResponse) (result ImportResult, err error) {
+	if len(listings) > MaxImportListings {
+		return result, fmt.Errorf("import of %d listings exceeds the maximum of %d", len(listings), MaxImportListings)
+	}
+
+	run := s.options.startRun(s.name, backendImport, dtos.AutoFilter{})
+	e := newExtraction()
+
+	valid := make([]*dtos.AutoFilterResponse, 0, len(listings))
+
+	defer func() {
+		err = s.options.finishRun(run, e, len(valid), err)
+	}()
+
+	result.Received = len(listings)
+	seen := make(map[string]bool)
+
+	for i, listing := range listings {
+		if reason, ok := importListing(listing, e); !ok {
+			e.skip(reason)
+			result.Rejected = append(result.Rejected, ImportRejection{Index: i, URL: listing.URL, Reason: reason})
+
+			continue
+		}
+
+		if seen[listing.URL] {
+			e.skip(skipDuplicate)
+			result.Duplicates++
+
+			continue
+		}
+
+		seen[listing.URL] = true
+		valid = append(valid, listing)
+		e.done()
+	}
+
+	added, err := s.store.Save(ctx, valid)
+	if err != nil {
+		return result, err
+	}
+
+	result.Imported = added
+	result.Updated = len(valid) - added
+
+	if s.options.quality != nil {
+		s.options.quality.record(s.name, QualityPolicy{}, e)
+	}
+
+	return result, nil
+}
+
+// importListing trims an imported listing and checks it holds what a scrape
+// extracts: an absolute URL, a title and a positive price. Invalid images
+// and years are dropped. It returns the skip reason of invalid listings.
+func importListing(auto *dtos.AutoFilterResponse, e *extraction) (string, bool) {
+	e.item()
+
+	auto.URL = strings.TrimSpace(auto.URL)
+	auto.Title = strings.TrimSpace(auto.Title)
+	auto.Brand = strings.TrimSpace(auto.Brand)
+	auto.Model = strings.TrimSpace(auto.Model)
+
+	required := []struct {
+		field string
+		err   error
+	}{
+		{FieldURL, checkListingURL(auto.URL)},
+		{FieldTitle, checkListingTitle(auto.Title)},
+		{FieldPrice, checkListingPrice(auto.Price)},
+	}
+
+	for _, check := range required {
+		if outcome := e.optional(check.field, check.err); outcome != FieldFound {
+			return check.field + "_" + outcome, false
+		}
+	}
+
+	var images []string
+
+	for _, image := range append([]string{auto.ImageURL}, auto.ImageURLs...) {
+		image = strings.TrimSpace(image)
+		if image == "" || slices.Contains(images, image) {
+			continue
+		}
+
+		if e.optional(FieldImages, checkListingURL(image)) == FieldFound {
+			images = append(images, image)
+		}
+	}
+
+	auto.ImageURL = ""
+	auto.ImageURLs = images
+
+	if len(images) > 0 {
+		auto.ImageURL = images[0]
+	}
+
+	if auto.Year > 0 && e.optional(FieldYear, checkListingYear(auto.Year)) != FieldFound {
+		auto.Year = 0
+	}
+
+	return "", true
+}
+
+func checkListingURL(value string) error {
+	if value == "" {
+		return ErrFieldNotFound
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute http URL", value)
+	}
+
+	return nil
+}
+
+func checkListingTitle(value string) error {
+	if value == "" {
+		return ErrFieldNotFound
+	}
+
+	return nil
+}
+
+func checkListingPrice(value float64) error {
+	switch {
+	case value == 0:
+		return ErrFieldNotFound
+	case value < 0 || math.IsNaN(value) || math.IsInf(value, 0):
+		return errors.New("price must be positive")
+	}
+
+	return nil
+}
+
+func checkListingYear(value uint32) error {
+	if value < minListingYear || int(value) > time.Now().Year()+1 {
+		return fmt.Errorf("year %d out of range", value)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+)
+
+// memoryImportStore keeps the imported listings in memory, for tests.
+type memoryImportStore struct {
+	mu       sync.Mutex
+	listings map[string]*dtos.AutoFilterResponse
+}
+
+func (s *memoryImportStore) Save(ctx context.Context, listings []*dtos.AutoFilterResponse) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listings == nil {
+		s.listings = make(map[string]*dtos.AutoFilterResponse)
+	}
+
+	added := 0
+	for _, listing := range listings {
+		if _, ok := s.listings[listing.URL]; !ok {
+			added++
+		}
+
+		s.listings[listing.URL] = listing
+	}
+
+	return added, nil
+}
+
+// Find narrows the listings like the indexes of RedisImportStore: by brand,
+// keeping those without one, and by price.
+func (s *memoryImportStore) Find(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listings := make([]*dtos.AutoFilterResponse, 0, len(s.listings))
+	for _, listing := range s.listings {
+		if strings.TrimSpace(filter.Brand) != "" && listing.Brand != "" && importBrandKey(listing.Brand) != importBrandKey(filter.Brand) {
+			continue
+		}
+
+		if filter.MinPrice != nil && *filter.MinPrice > 0 && listing.Price < *filter.MinPrice {
+			continue
+		}
+
+		if filter.MaxPrice != nil && *filter.MaxPrice > 0 && listing.Price > *filter.MaxPrice {
+			continue
+		}
+
+		auto := *listing
+		listings = append(listings, &auto)
+	}
+
+	return listings, nil
+}
+
+func TestImportSourceImport(t *testing.T) {
+	store := &memoryImportStore{}
+	runs := &memoryRunLog{}
+	quality := NewQuality()
+
+	s := NewImportSource("Imported", store, WithRunLog(runs), WithQuality(quality))
+
+	result, err := s.Import(context.Background(), []*dtos.AutoFilterResponse{
+		{Title: " Toyota Yaris 2019 ", Price: 12500, URL: "https://dealer.example/yaris", ImageURLs: []string{"https://dealer.example/yaris.jpg", "yaris-2.jpg"}, Brand: "Toyota", Model: "Yaris", Year: 2019},
+		{Title: "Kia Rio 2017", Price: 9800, URL: "https://dealer.example/rio", Year: 1800},
+		{Title: "Toyota Yaris again", Price: 12000, URL: "https://dealer.example/yaris"},
+		{Title: "No price", URL: "https://dealer.example/no-price"},
+		{Title: "Relative URL", Price: 5000, URL: "/relative"},
+		{Title: "Negative price", Price: -1, URL: "https://dealer.example/negative"},
+	})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	want := ImportResult{
+		Received:   6,
+		Imported:   2,
+		Duplicates: 1,
+		Rejected: []ImportRejection{
+			{Index: 3, URL: "https://dealer.example/no-price", Reason: "price_missing"},
+			{Index: 4, URL: "/relative", Reason: "url_invalid"},
+			{Index: 5, URL: "https://dealer.example/negative", Reason: "price_invalid"},
+		},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("Import() = %+v, want %+v", result, want)
+	}
+
+	autos, err := s.FindByFilter(context.Background(), dtos.AutoFilter{Brand: "toyota"})
+	if err != nil {
+		t.Fatalf("FindByFilter() error = %v", err)
+	}
+
+	if len(autos) != 1 {
+		t.Fatalf("FindByFilter() found %d listings, want 1", len(autos))
+	}
+
+	yaris := autos[0]
+	if yaris.Title != "Toyota Yaris 2019" || yaris.ImageURL != "https://dealer.example/yaris.jpg" || len(yaris.ImageURLs) != 1 {
+		t.Errorf("imported listing = %+v, want trimmed title and only the absolute image", yaris)
+	}
+
+	// the Rio has no brand, only its title names it
+	rio, _ := s.FindByFilter(context.Background(), dtos.AutoFilter{Brand: "kia", MaxPrice: float64Ptr(10000)})
+	if len(rio) != 1 || rio[0].Year != 0 {
+		t.Errorf("FindByFilter(kia) = %+v, want the Rio without its invalid year", rio)
+	}
+
+	result, err = s.Import(context.Background(), []*dtos.AutoFilterResponse{
+		{Title: "Toyota Yaris 2019", Price: 11900, URL: "https://dealer.example/yaris"},
+	})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+
+	if result.Imported != 0 || result.Updated != 1 {
+		t.Errorf("re-import = %+v, want 1 updated", result)
+	}
+
+	recorded, _ := runs.List(context.Background(), RunQuery{Source: "Imported"})
+	if len(recorded) != 2 || recorded[1].Backend != backendImport || recorded[1].Seen != 6 || recorded[1].Found != 2 || recorded[1].Skipped != 4 {
+		t.Errorf("recorded runs = %+v, want the first import with 6 seen, 2 found and 4 skipped", recorded)
+	}
+
+	sources := quality.Sources()
+	if len(sources) != 1 || sources[0].Skipped["price_missing"] != 1 || sources[0].Skipped[skipDuplicate] != 1 {
+		t.Errorf("quality = %+v, want the import skips", sources)
+	}
+}
+
+func TestImportPriceBound(t *testing.T) {
+	tests := []struct {
+		bound *float64
+		want  string
+	}{
+		{bound: nil, want: "-inf"},
+		{bound: float64Ptr(0), want: "-inf"},
+		{bound: float64Ptr(12500.5), want: "12500.5"},
+	}
+
+	for _, tt := range tests {
+		if got := importPriceBound(tt.bound, "-inf"); got != tt.want {
+			t.Errorf("importPriceBound(%v) = %q, want %q", tt.bound, got, tt.want)
+		}
+	}
+
+	if got := importBrandKey(" Toyota "); got != "listings:imported:brand:toyota" {
+		t.Errorf("importBrandKey() = %q, want the key of toyota", got)
+	}
+}
@@ -0,0 +1,566 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/redis/go-redis/v9"
+)
+
+// Listing statuses. A listing missing from the scrapes of a filter it used
+// to appear in is probed: a 404 or 410 detail page means it was removed, a
+// detail page titled "vendido" that it was sold.
+const (
+	ListingActive  = "active"
+	ListingSold    = "sold"
+	ListingRemoved = "removed"
+)
+
+const (
+	lifecycleKey             = "listings:lifecycle"
+	lifecycleRemovedKey      = "listings:removed"
+	lifecycleSeenKeyPrefix   = "lifecycle:seen:"
+	defaultLifecycleMisses   = 2
+	maxLifecycleProbes       = 20
+	lifecycleTimeout         = 5 * time.Second
+	probeTimeout             = 15 * time.Second
+	maxProbeSize             = 2 << 20
+	defaultRemovedQueryLimit = 20
+	maxRemovedQueryLimit     = 200
+	lifecycleQueueSize       = 64
+	lifecycleWorkers         = 2
+)
+
+// lifecyclePoliteness paces the probes of missing listings, which go to the
+// detail pages of every source. They share the budget of the host with its
+// searches, so on a host with a stricter profile the stricter limits apply.
+var lifecyclePoliteness = PolitenessPolicy{
+	RequestsPerMinute:  20,
+	Burst:              2,
+	MaxConcurrentPages: 1,
+}
+
+// ListingLifecycle is the life of a listing on the market, from the first
+// scrape that found it until it was confirmed sold or removed.
+type ListingLifecycle struct {
+	URL       string    `json:"url"`
+	Source    string    `json:"source"`
+	Title     string    `json:"title"`
+	Price     float64   `json:"price"`
+	Status    string    `json:"status"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	RemovedAt time.Time `json:"removed_at"`
+	// Misses counts the scrapes in a row the listing was missing from.
+	Misses int `json:"misses"`
+}
+
+// DaysOnMarket is the number of whole days the listing was, or has been so
+// far, on the market.
+func (l ListingLifecycle) DaysOnMarket() int {
+	end := l.LastSeen
+	if !l.RemovedAt.IsZero() {
+		end = l.RemovedAt
+	}
+
+	return int(end.Sub(l.FirstSeen).Hours() / 24)
+}
+
+// RemovedQuery selects the most recently removed listings, optionally of one
+// source.
+type RemovedQuery struct {
+	Source string
+	Limit  int
+}
+
+func (q RemovedQuery) limit() int {
+	if q.Limit <= 0 {
+		return defaultRemovedQueryLimit
+	}
+
+	return min(q.Limit, maxRemovedQueryLimit)
+}
+
+// LifecycleStore keeps the lifecycle of the listings by URL, and the URLs
+// the last scrape of every filter found.
+type LifecycleStore interface {
+	// Seen returns the URLs of the scrapes of a filter, by its key.
+	Seen(ctx context.Context, key string) ([]string, error)
+	SetSeen(ctx context.Context, key string, urls []string) error
+	// Listings returns the lifecycles of the urls that are tracked.
+	Listings(ctx context.Context, urls []string) (map[string]*ListingLifecycle, error)
+	Save(ctx context.Context, listings []*ListingLifecycle) error
+	// Removed returns the listings matching query, most recently removed
+	// first.
+	Removed(ctx context.Context, query RemovedQuery) ([]ListingLifecycle, error)
+}
+
+// RedisLifecycleStore keeps the lifecycles in the listings:lifecycle Redis
+// hash, the removed listings in the listings:removed sorted set and the URLs
+// of every filter in a lifecycle:seen:<filter key> set.
+type RedisLifecycleStore struct {
+	client *redis.Client
+}
+
+func NewRedisLifecycleStore(client *redis.Client) *RedisLifecycleStore {
+	return &RedisLifecycleStore{client: client}
+}
+
+func (s *RedisLifecycleStore) Seen(ctx context.Context, key string) ([]string, error) {
+	return s.client.SMembers(ctx, lifecycleSeenKeyPrefix+key).Result()
+}
+
+func (s *RedisLifecycleStore) SetSeen(ctx context.Context, key string, urls []string) error {
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, lifecycleSeenKeyPrefix+key)
+
+		if len(urls) > 0 {
+			members := make([]any, 0, len(urls))
+			for _, url := range urls {
+				members = append(members, url)
+			}
+
+			pipe.SAdd(ctx, lifecycleSeenKeyPrefix+key, members...)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (s *RedisLifecycleStore) Listings(ctx context.Context, urls []string) (map[string]*ListingLifecycle, error) {
+	listings := make(map[string]*ListingLifecycle, len(urls))
+	if len(urls) == 0 {
+		return listings, nil
+	}
+
+	values, err := s.client.HMGet(ctx, lifecycleKey, urls...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var listing ListingLifecycle
+		if err := json.Unmarshal([]byte(data), &listing); err != nil {
+			continue
+		}
+
+		listings[listing.URL] = &listing
+	}
+
+	return listings, nil
+}
+
+func (s *RedisLifecycleStore) Save(ctx context.Context, listings []*ListingLifecycle) error {
+	if len(listings) == 0 {
+		return nil
+	}
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, listing := range listings {
+			data, err := json.Marshal(listing)
+			if err != nil {
+				return err
+			}
+
+			pipe.HSet(ctx, lifecycleKey, listing.URL, string(data))
+
+			if listing.Status == ListingActive {
+				pipe.ZRem(ctx, lifecycleRemovedKey, listing.URL)
+				continue
+			}
+
+			pipe.ZAdd(ctx, lifecycleRemovedKey, redis.Z{
+				Score:  float64(listing.RemovedAt.Unix()),
+				Member: listing.URL,
+			})
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (s *RedisLifecycleStore) Removed(ctx context.Context, query RemovedQuery) ([]ListingLifecycle, error) {
+	limit := query.limit()
+	removed := make([]ListingLifecycle, 0, limit)
+
+	// source queries read the removed listings in batches until enough match
+	for start := int64(0); len(removed) < limit; start += int64(limit) {
+		urls, err := s.client.ZRevRange(ctx, lifecycleRemovedKey, start, start+int64(limit)-1).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		listings, err := s.Listings(ctx, urls)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, url := range urls {
+			listing, ok := listings[url]
+			if !ok || (query.Source != "" && !strings.EqualFold(query.Source, listing.Source)) {
+				continue
+			}
+
+			if len(removed) < limit {
+				removed = append(removed, *listing)
+			}
+		}
+
+		if len(urls) < limit {
+			break
+		}
+	}
+
+	return removed, nil
+}
+
+// Lifecycle follows the listings through the repeated scrapes of the same
+// filters. Listings missing from misses scrapes in a row are probed to
+// confirm they were sold or removed.
+type Lifecycle struct {
+	store   LifecycleStore
+	misses  int
+	options scrapperOptions
+	client  *http.Client
+	queue   chan observation
+
+	// mu serializes the reads and writes of the filters; probes run unlocked
+	mu sync.Mutex
+}
+
+// observation is a scrape of filter in source to observe in the background.
+type observation struct {
+	source string
+	filter dtos.AutoFilter
+	autos  []*dtos.AutoFilterResponse
+}
+
+// NewLifecycle probes the listings missing from misses scrapes in a row, 2
+// when it is not positive.
+func NewLifecycle(store LifecycleStore, misses int, opts ...Option) *Lifecycle {
+	if misses <= 0 {
+		misses = defaultLifecycleMisses
+	}
+
+	return &Lifecycle{
+		store:   store,
+		misses:  misses,
+		options: newScrapperOptions(opts),
+		client:  &http.Client{Timeout: probeTimeout},
+		queue:   make(chan observation, lifecycleQueueSize),
+	}
+}
+
+// Removed returns the listings confirmed sold or removed.
+func (l *Lifecycle) Removed(ctx context.Context, query RemovedQuery) ([]ListingLifecycle, error) {
+	return l.store.Removed(ctx, query)
+}
+
+// Track returns scrapper, recording the lifecycle of the listings it finds as
+// source. Replayed fixtures are not tracked.
+func (l *Lifecycle) Track(source string, scrapper AutoScrapper) AutoScrapper {
+	if l.options.fixtureMode == FixtureModeReplay {
+		return scrapper
+	}
+
+	return &trackedScrapper{
+		lifecycle: l,
+		source:    source,
+		scrapper:  scrapper,
+	}
+}
+
+// trackedScrapper queues the results of a scrapper to be observed by Run.
+type trackedScrapper struct {
+	lifecycle *Lifecycle
+	source    string
+	scrapper  AutoScrapper
+}
+
+func (s *trackedScrapper) FindByFilter(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+	autos, err := s.scrapper.FindByFilter(ctx, filter)
+
+	// an empty scrape more likely failed quietly than emptied the market
+	if err == nil && len(autos) > 0 {
+		s.lifecycle.enqueue(observation{source: s.source, filter: filter, autos: autos})
+	}
+
+	return autos, err
+}
+
+// enqueue queues an observation. Observations are dropped while the queue is
+// full; the next scrape of the filter observes its listings again.
+func (l *Lifecycle) enqueue(o observation) {
+	select {
+	case l.queue <- o:
+	default:
+		log.Println(o.source, "lifecycle queue full, dropping the observation of", NormalizeFilter(o.filter))
+	}
+}
+
+// Run observes the queued scrapes until ctx is done.
+func (l *Lifecycle) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for range lifecycleWorkers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case o := <-l.queue:
+					if err := l.Observe(ctx, o.source, o.filter, o.autos); err != nil {
+						log.Println(o.source, "tracking listing lifecycle failed:", err)
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// Observe records the listings a scrape of filter found in source. Those
+// the previous scrapes of filter found and this one did not count a miss.
+func (l *Lifecycle) Observe(ctx context.Context, source string, filter dtos.AutoFilter, autos []*dtos.AutoFilterResponse) error {
+	key := source + "?" + NormalizeFilter(filter)
+	now := time.Now()
+
+	probes, err := l.record(ctx, key, source, autos, now)
+	if err != nil || len(probes) == 0 {
+		return err
+	}
+
+	// other observations go on while the probes wait on the politeness policy
+	verdicts := make(map[string]string, len(probes))
+
+	for _, url := range probes {
+		status, err := l.probe(ctx, url)
+		if err != nil {
+			log.Println(source, "probing missing listing", url, "failed:", err)
+			continue
+		}
+
+		verdicts[url] = status
+	}
+
+	return l.settle(ctx, key, verdicts, now)
+}
+
+// record saves the listings of a scrape of the filter at key and counts the
+// misses of those it did not find. It returns the missing listings to probe,
+// which stay seen by the filter until their verdict.
+func (l *Lifecycle) record(ctx context.Context, key string, source string, autos []*dtos.AutoFilterResponse, now time.Time) ([]string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, lifecycleTimeout)
+	defer cancel()
+
+	previous, err := l.store.Seen(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]*dtos.AutoFilterResponse, len(autos))
+	urls := make([]string, 0, len(autos))
+
+	for _, auto := range autos {
+		if _, ok := found[auto.URL]; !ok {
+			found[auto.URL] = auto
+			urls = append(urls, auto.URL)
+		}
+	}
+
+	var missing []string
+	for _, url := range previous {
+		if _, ok := found[url]; !ok {
+			missing = append(missing, url)
+		}
+	}
+
+	listings, err := l.store.Listings(ctx, append(urls, missing...))
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make([]*ListingLifecycle, 0, len(urls)+len(missing))
+
+	for _, url := range urls {
+		listing, ok := listings[url]
+		if !ok {
+			listing = &ListingLifecycle{URL: url, Source: source, FirstSeen: now}
+		}
+
+		// a listing back on the market was relisted, not sold
+		listing.Status = ListingActive
+		listing.RemovedAt = time.Time{}
+		listing.LastSeen = now
+		listing.Misses = 0
+		listing.Title = found[url].Title
+		listing.Price = found[url].Price
+
+		changed = append(changed, listing)
+	}
+
+	// URLs still missing without a verdict are kept for the next scrapes
+	seen := urls
+
+	var probes []string
+
+	for _, url := range missing {
+		listing, ok := listings[url]
+		if !ok || listing.Status != ListingActive {
+			continue
+		}
+
+		listing.Misses++
+		changed = append(changed, listing)
+		seen = append(seen, url)
+
+		if listing.Misses >= l.misses && len(probes) < maxLifecycleProbes {
+			probes = append(probes, url)
+		}
+	}
+
+	if err := l.store.Save(ctx, changed); err != nil {
+		return nil, err
+	}
+
+	if err := l.store.SetSeen(ctx, key, seen); err != nil {
+		return nil, err
+	}
+
+	return probes, nil
+}
+
+// settle applies the verdicts of the probes of the filter at key made after
+// the scrape at scraped. Listings found again since keep their status.
+// Listings with a verdict leave the filter: sold and removed ones are off the
+// market, active ones only out of the results of the filter.
+func (l *Lifecycle) settle(ctx context.Context, key string, verdicts map[string]string, scraped time.Time) error {
+	if len(verdicts) == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// the probes may outlast a timeout shared with record
+	ctx, cancel := context.WithTimeout(ctx, lifecycleTimeout)
+	defer cancel()
+
+	urls := make([]string, 0, len(verdicts))
+	for url := range verdicts {
+		urls = append(urls, url)
+	}
+
+	listings, err := l.store.Listings(ctx, urls)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	changed := make([]*ListingLifecycle, 0, len(verdicts))
+	settled := make(map[string]bool, len(verdicts))
+
+	for url, status := range verdicts {
+		listing, ok := listings[url]
+		if !ok || listing.Status != ListingActive || listing.LastSeen.After(scraped) {
+			continue
+		}
+
+		settled[url] = true
+
+		if status == ListingActive {
+			listing.Misses = 0
+		} else {
+			listing.Status = status
+			listing.RemovedAt = now
+		}
+
+		changed = append(changed, listing)
+	}
+
+	if err := l.store.Save(ctx, changed); err != nil {
+		return err
+	}
+
+	previous, err := l.store.Seen(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	seen := make([]string, 0, len(previous))
+	for _, url := range previous {
+		if !settled[url] {
+			seen = append(seen, url)
+		}
+	}
+
+	return l.store.SetSeen(ctx, key, seen)
+}
+
+// probe requests the detail page of a listing and returns its status.
+func (l *Lifecycle) probe(ctx context.Context, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("Accept-Language", "es-PE,es;q=0.9")
+
+	release, err := l.options.acquirePage(ctx, pageURL, lifecyclePoliteness)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	resp, err := l.options.send(l.client, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return ListingRemoved, nil
+	case http.StatusOK:
+	default:
+		return "", &statusError{code: resp.StatusCode, url: pageURL}
+	}
+
+	doc, err := goquery.NewDocumentFromReader(io.LimitReader(resp.Body, maxProbeSize))
+	if err != nil {
+		return "", err
+	}
+
+	// only the title and headings, menus often link to the "más vendidos"
+	if strings.Contains(strings.ToLower(doc.Find("title, h1, h2").Text()), "vendido") {
+		return ListingSold, nil
+	}
+
+	return ListingActive, nil
+}
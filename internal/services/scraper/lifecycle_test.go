@@ -0,0 +1,186 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+)
+
+// memoryLifecycleStore keeps the lifecycles in memory, for tests.
+type memoryLifecycleStore struct {
+	mu       sync.Mutex
+	seen     map[string][]string
+	listings map[string]ListingLifecycle
+}
+
+func newMemoryLifecycleStore() *memoryLifecycleStore {
+	return &memoryLifecycleStore{
+		seen:     make(map[string][]string),
+		listings: make(map[string]ListingLifecycle),
+	}
+}
+
+func (s *memoryLifecycleStore) Seen(ctx context.Context, key string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.seen[key]...), nil
+}
+
+func (s *memoryLifecycleStore) SetSeen(ctx context.Context, key string, urls []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seen[key] = append([]string(nil), urls...)
+
+	return nil
+}
+
+func (s *memoryLifecycleStore) Listings(ctx context.Context, urls []string) (map[string]*ListingLifecycle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listings := make(map[string]*ListingLifecycle)
+	for _, url := range urls {
+		if listing, ok := s.listings[url]; ok {
+			listings[url] = &listing
+		}
+	}
+
+	return listings, nil
+}
+
+func (s *memoryLifecycleStore) Save(ctx context.Context, listings []*ListingLifecycle) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, listing := range listings {
+		s.listings[listing.URL] = *listing
+	}
+
+	return nil
+}
+
+func (s *memoryLifecycleStore) Removed(ctx context.Context, query RemovedQuery) ([]ListingLifecycle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed []ListingLifecycle
+	for _, listing := range s.listings {
+		if listing.Status != ListingActive && (query.Source == "" || query.Source == listing.Source) {
+			removed = append(removed, listing)
+		}
+	}
+
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].URL < removed[j].URL
+	})
+
+	return removed, nil
+}
+
+func TestLifecycleConfirmsRemovals(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/live", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title>Toyota Yaris 2019</title></head><body><a href="/top">Los más vendidos</a></body></html>`))
+	})
+	mux.HandleFunc("/sold", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title>Kia Rio 2017</title></head><body><h1>Auto vendido</h1></body></html>`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	store := newMemoryLifecycleStore()
+	lifecycle := NewLifecycle(store, 2)
+
+	listing := func(path string) *dtos.AutoFilterResponse {
+		return &dtos.AutoFilterResponse{Title: path, Price: 10000, URL: srv.URL + path}
+	}
+
+	observe := func(autos ...*dtos.AutoFilterResponse) {
+		t.Helper()
+
+		if err := lifecycle.Observe(context.Background(), "NeoAuto", dtos.AutoFilter{Brand: "Toyota"}, autos); err != nil {
+			t.Fatalf("Observe() error = %v", err)
+		}
+	}
+
+	observe(listing("/kept"), listing("/live"), listing("/gone"), listing("/sold"))
+
+	// missing once, not probed yet
+	observe(listing("/kept"))
+
+	if removed, _ := lifecycle.Removed(context.Background(), RemovedQuery{}); len(removed) != 0 {
+		t.Fatalf("Removed() after one miss = %+v, want none", removed)
+	}
+
+	observe(listing("/kept"))
+
+	removed, err := lifecycle.Removed(context.Background(), RemovedQuery{Source: "NeoAuto"})
+	if err != nil {
+		t.Fatalf("Removed() error = %v", err)
+	}
+
+	if len(removed) != 2 {
+		t.Fatalf("Removed() = %+v, want the gone and sold listings", removed)
+	}
+
+	for i, want := range []struct{ url, status string }{
+		{srv.URL + "/gone", ListingRemoved},
+		{srv.URL + "/sold", ListingSold},
+	} {
+		if removed[i].URL != want.url || removed[i].Status != want.status || removed[i].RemovedAt.IsZero() {
+			t.Errorf("Removed()[%d] = %+v, want %s %s with removed_at", i, removed[i], want.url, want.status)
+		}
+	}
+
+	live := store.listings[srv.URL+"/live"]
+	if live.Status != ListingActive || live.Misses != 0 {
+		t.Errorf("live listing = %+v, want active without misses", live)
+	}
+
+	seen, _ := store.Seen(context.Background(), "NeoAuto?brand=toyota")
+	if len(seen) != 1 {
+		t.Errorf("seen URLs = %v, want only the kept listing", seen)
+	}
+
+	// back on the market
+	observe(listing("/kept"), listing("/gone"))
+
+	if gone := store.listings[srv.URL+"/gone"]; gone.Status != ListingActive || !gone.RemovedAt.IsZero() {
+		t.Errorf("relisted listing = %+v, want active", gone)
+	}
+}
+
+func TestLifecycleTrackObservesInTheBackground(t *testing.T) {
+	store := newMemoryLifecycleStore()
+	lifecycle := NewLifecycle(store, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go lifecycle.Run(ctx)
+
+	scrapper := lifecycle.Track("NeoAuto", scrapperFunc(func(filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+		return []*dtos.AutoFilterResponse{{Title: "Toyota Yaris", Price: 10000, URL: "https://neoauto.test/yaris"}}, nil
+	}))
+
+	if _, err := scrapper.FindByFilter(context.Background(), dtos.AutoFilter{Brand: "Toyota"}); err != nil {
+		t.Fatalf("FindByFilter() error = %v", err)
+	}
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if listings, _ := store.Listings(ctx, []string{"https://neoauto.test/yaris"}); len(listings) == 1 {
+			return
+		}
+	}
+
+	t.Fatal("tracked listing was never observed")
+}
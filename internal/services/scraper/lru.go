@@ -0,0 +1,67 @@
+package services
+
+import "container/list"
+
+// lru is a map holding at most size entries, evicting the least recently
+// used one to make room. It is not safe for concurrent use.
+type lru[K comparable, V any] struct {
+	size    int
+	order   *list.List
+	entries map[K]*list.Element
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func newLRU[K comparable, V any](size int) *lru[K, V] {
+	return &lru[K, V]{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[K]*list.Element),
+	}
+}
+
+// Get returns the value of key, marking it used.
+func (c *lru[K, V]) Get(key K) (V, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return element.Value.(*lruEntry[K, V]).value, true
+}
+
+// Put sets the value of key, marking it used.
+func (c *lru[K, V]) Put(key K, value V) {
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry[K, V]).key)
+	}
+}
+
+// Remove deletes key.
+func (c *lru[K, V]) Remove(key K) {
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+}
+
+func (c *lru[K, V]) Len() int {
+	return c.order.Len()
+}
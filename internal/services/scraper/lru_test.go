@@ -0,0 +1,31 @@
+package services
+
+import "testing"
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRU[string, int](2)
+
+	c.Put("a", 1)
+	c.Put("b", 2)
+
+	// a is used last, so b goes first
+	if value, ok := c.Get("a"); !ok || value != 1 {
+		t.Fatalf("Get(a) = %d, %v, want 1", value, ok)
+	}
+
+	c.Put("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) found the least recently used entry, want it evicted")
+	}
+
+	if value, ok := c.Get("c"); !ok || value != 3 || c.Len() != 2 {
+		t.Errorf("Get(c) = %d, %v with %d entries, want 3 with 2 entries", value, ok, c.Len())
+	}
+
+	c.Remove("a")
+
+	if _, ok := c.Get("a"); ok || c.Len() != 1 {
+		t.Errorf("Get(a) after Remove found it with %d entries, want it gone", c.Len())
+	}
+}
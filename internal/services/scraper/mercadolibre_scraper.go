@@ -0,0 +1,470 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/enums"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/fixtures"
+)
+
+const (
+	mercadoLibreBaseURL = "https://api.mercadolibre.com/"
+	mercadoLibreSite    = "MPE"
+	// mercadoLibreCategory is "Autos y Camionetas" in Peru.
+	mercadoLibreCategory = "MPE1744"
+	mercadoLibrePageSize = 50
+	mercadoLibrePages    = 3
+
+	// backendAPI is the backend of the scrappers calling a JSON API.
+	backendAPI = "api"
+)
+
+// Attribute IDs of the Mercado Libre vehicles taxonomy.
+const (
+	mercadoLibreBrand      = "BRAND"
+	mercadoLibreModel      = "MODEL"
+	mercadoLibreYear       = "VEHICLE_YEAR"
+	mercadoLibreKilometers = "KILOMETERS"
+)
+
+// skipFilterMismatch is the skip reason of listings outside the filter, which
+// the search API matches loosely.
+const skipFilterMismatch = "filter_mismatch"
+
+// mercadoLibreCurrency is the currency of the prices of every source. Peruvian
+// sellers also list in soles, which are skipped rather than compared with
+// dollar budgets.
+const (
+	mercadoLibreCurrency = "USD"
+	skipCurrency         = "currency_not_usd"
+)
+
+// mercadoLibrePoliteness is the policy of the search API. It is an API, so
+// robots.txt does not apply.
+var mercadoLibrePoliteness = PolitenessPolicy{
+	RequestsPerMinute: 60,
+	Burst:             3,
+	IgnoreRobots:      true,
+}
+
+// MercadoLibreScrapper searches the vehicles of Mercado Libre Peru through
+// its items search JSON API. The access token is optional for the public
+// search but raises its rate limits.
+type MercadoLibreScrapper struct {
+	accessToken string
+	options     scrapperOptions
+	client      *http.Client
+}
+
+func NewMercadoLibreScrapper(accessToken string, opts ...Option) *MercadoLibreScrapper {
+	return &MercadoLibreScrapper{
+		accessToken: accessToken,
+		options:     newScrapperOptions(opts),
+		client:      &http.Client{Timeout: httpScrapperTimeout},
+	}
+}
+
+// mercadoLibreSearch is the part of a search API response the scrapper reads.
+type mercadoLibreSearch struct {
+	Paging struct {
+		Total  int `json:"total"`
+		Offset int `json:"offset"`
+		Limit  int `json:"limit"`
+	} `json:"paging"`
+	Results []mercadoLibreItem `json:"results"`
+}
+
+type mercadoLibreItem struct {
+	ID         string                  `json:"id"`
+	Title      string                  `json:"title"`
+	Price      float64                 `json:"price"`
+	CurrencyID string                  `json:"currency_id"`
+	Permalink  string                  `json:"permalink"`
+	Thumbnail  string                  `json:"thumbnail"`
+	Attributes []mercadoLibreAttribute `json:"attributes"`
+}
+
+type mercadoLibreAttribute struct {
+	ID          string `json:"id"`
+	ValueName   string `json:"value_name"`
+	ValueStruct *struct {
+		Number float64 `json:"number"`
+		Unit   string  `json:"unit"`
+	} `json:"value_struct"`
+}
+
+// attribute returns the value of the attribute with the given ID.
+func (i mercadoLibreItem) attribute(id string) (mercadoLibreAttribute, bool) {
+	for _, attribute := range i.Attributes {
+		if attribute.ID == id && attribute.ValueName != "" {
+			return attribute, true
+		}
+	}
+
+	return mercadoLibreAttribute{}, false
+}
+
+// number returns the numeric value of the attribute, e.g. 45000 for
+// "45.000 km".
+func (a mercadoLibreAttribute) number() (uint32, bool) {
+	if a.ValueStruct != nil {
+		return uint32(a.ValueStruct.Number), true
+	}
+
+	return digitsNumber(a.ValueName)
+}
+
+// digitsNumber reads the digits of value as a number, ignoring separators
+// and units.
+func digitsNumber(value string) (uint32, bool) {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+
+		return -1
+	}, value)
+
+	number, err := strconv.ParseUint(digits, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return uint32(number), true
+}
+
+func (s *MercadoLibreScrapper) FindByFilter(ctx context.Context, filter dtos.AutoFilter) (autos []*dtos.AutoFilterResponse, err error) {
+	source := enums.MercadoLibre.String()
+
+	if err := s.options.checkCooldown(source); err != nil {
+		return nil, err
+	}
+
+	run := s.options.startRun(source, backendAPI, filter)
+	e := newExtraction()
+
+	defer func() {
+		err = s.options.updateCooldown(source, err)
+		err = s.options.finishRun(run, e, len(autos), err)
+	}()
+
+	// the listings carry the catalog names, e.g. Volkswagen for "VW"
+	filter = s.options.catalog.Canonical(filter)
+
+	autos = make([]*dtos.AutoFilterResponse, 0)
+	seen := make(map[string]bool)
+
+	for index := 0; index < mercadoLibrePages; index++ {
+		searchURL, err := s.searchURL(filter, index*mercadoLibrePageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		if index == 0 {
+			run.URL = searchURL
+		}
+
+		run.Pages++
+
+		search, err := s.search(ctx, run, searchURL)
+		if err != nil {
+			if index == 0 {
+				return nil, err
+			}
+
+			break
+		}
+
+		for _, item := range search.Results {
+			auto, ok := s.listing(item, filter, e)
+			if !ok {
+				continue
+			}
+
+			if seen[auto.URL] {
+				e.skip(skipDuplicate)
+				continue
+			}
+
+			seen[auto.URL] = true
+			autos = append(autos, auto)
+			e.done()
+		}
+
+		if len(search.Results) < mercadoLibrePageSize || (index+1)*mercadoLibrePageSize >= search.Paging.Total {
+			break
+		}
+	}
+
+	// a canceled search ends between pages, without its later results
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if s.options.quality != nil {
+		s.options.quality.record(source, QualityPolicy{}, e)
+	}
+
+	return autos, nil
+}
+
+// searchURL returns the search API URL of a page of results for filter. The
+// brand and model go in the full text query since the taxonomy filters take
+// value IDs; listings are checked against the filter afterwards.
+func (s *MercadoLibreScrapper) searchURL(filter dtos.AutoFilter, offset int) (string, error) {
+	baseURL := s.options.baseURL
+	if baseURL == "" {
+		baseURL = mercadoLibreBaseURL
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	u = u.JoinPath("sites", mercadoLibreSite, "search")
+
+	query := url.Values{}
+	query.Set("category", mercadoLibreCategory)
+	query.Set("limit", strconv.Itoa(mercadoLibrePageSize))
+	query.Set("offset", strconv.Itoa(offset))
+
+	if q := strings.TrimSpace(filter.Brand + " " + filter.Model); q != "" {
+		query.Set("q", strings.ToLower(q))
+	}
+
+	if years := mercadoLibreRange(uintBound(filter.MinYear), uintBound(filter.MaxYear)); years != "" {
+		query.Set(mercadoLibreYear, years)
+	}
+
+	if prices := mercadoLibreRange(floatBound(filter.MinPrice), floatBound(filter.MaxPrice)); prices != "" {
+		query.Set("price", prices)
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// mercadoLibreRange formats a range filter, with * for an open bound.
+func mercadoLibreRange(lower, upper string) string {
+	if lower == "" && upper == "" {
+		return ""
+	}
+
+	if lower == "" {
+		lower = "*"
+	}
+
+	if upper == "" {
+		upper = "*"
+	}
+
+	return lower + "-" + upper
+}
+
+func uintBound(bound *uint32) string {
+	if bound == nil || *bound == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(uint64(*bound), 10)
+}
+
+func floatBound(bound *float64) string {
+	if bound == nil || *bound <= 0 {
+		return ""
+	}
+
+	return strconv.FormatFloat(*bound, 'f', -1, 64)
+}
+
+// search gets a page of results, from the fixture directory in replay mode.
+func (s *MercadoLibreScrapper) search(ctx context.Context, run *scrapeRun, searchURL string) (*mercadoLibreSearch, error) {
+	body, replayed, err := s.options.readFixture(fixtures.JSONKey(searchURL))
+	if err != nil {
+		return nil, err
+	}
+
+	if !replayed {
+		body, err = s.download(ctx, run, searchURL)
+		if err != nil {
+			return nil, err
+		}
+
+		s.options.recordBody(fixtures.JSONKey(searchURL), body)
+	}
+
+	run.bytes.Add(int64(len(body)))
+
+	var search mercadoLibreSearch
+	if err := json.Unmarshal(body, &search); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", searchURL, err)
+	}
+
+	return &search, nil
+}
+
+func (s *MercadoLibreScrapper) download(ctx context.Context, run *scrapeRun, searchURL string) (body []byte, err error) {
+	release, err := s.options.acquirePage(ctx, searchURL, mercadoLibrePoliteness)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	proxy, err := s.options.nextProxy()
+	if err != nil {
+		return nil, err
+	}
+
+	run.useProxy(proxy)
+
+	defer func() {
+		s.options.reportProxy(proxy, err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if s.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.accessToken)
+	}
+
+	client := s.client
+	if proxy != nil {
+		client = &http.Client{Timeout: s.client.Timeout, Transport: proxy.Transport}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusTooManyRequests:
+		return nil, &BlockedError{Source: run.Source, Kind: BlockRateLimited}
+	case http.StatusForbidden:
+		return nil, &BlockedError{Source: run.Source, Kind: BlockAccessDenied}
+	}
+
+	return nil, &statusError{code: resp.StatusCode, url: searchURL}
+}
+
+// listing maps a search result. Results without permalink, title or price,
+// priced in another currency than dollars or outside the filter, are skipped.
+func (s *MercadoLibreScrapper) listing(item mercadoLibreItem, filter dtos.AutoFilter, e *extraction) (*dtos.AutoFilterResponse, bool) {
+	e.item()
+
+	if !e.require(FieldURL, mercadoLibreField(FieldURL, item.Permalink != "")) {
+		return nil, false
+	}
+
+	if !e.require(FieldTitle, mercadoLibreField(FieldTitle, item.Title != "")) {
+		return nil, false
+	}
+
+	if !e.require(FieldPrice, mercadoLibreField(FieldPrice, item.Price > 0)) {
+		return nil, false
+	}
+
+	if item.CurrencyID != mercadoLibreCurrency {
+		e.skip(skipCurrency)
+		return nil, false
+	}
+
+	auto := &dtos.AutoFilterResponse{
+		Title: item.Title,
+		Price: item.Price,
+		URL:   item.Permalink,
+	}
+
+	if item.Thumbnail != "" {
+		auto.ImageURL = strings.Replace(item.Thumbnail, "http://", "https://", 1)
+		auto.ImageURLs = []string{auto.ImageURL}
+	}
+
+	e.optional(FieldImage, mercadoLibreField(FieldImage, auto.ImageURL != ""))
+
+	if brand, ok := item.attribute(mercadoLibreBrand); ok {
+		auto.Brand = brand.ValueName
+	}
+
+	if model, ok := item.attribute(mercadoLibreModel); ok {
+		auto.Model = model.ValueName
+	}
+
+	if year, ok := item.attribute(mercadoLibreYear); ok {
+		auto.Year, _ = year.number()
+	}
+
+	if kilometers, ok := item.attribute(mercadoLibreKilometers); ok {
+		auto.Kilometers, _ = kilometers.number()
+	}
+
+	if !matchesFilter(auto, filter) {
+		e.skip(skipFilterMismatch)
+		return nil, false
+	}
+
+	return auto, true
+}
+
+func mercadoLibreField(name string, found bool) error {
+	if found {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+}
+
+// matchesFilter reports whether auto is within filter. Attributes the
+// listing does not publish are not checked.
+func matchesFilter(auto *dtos.AutoFilterResponse, filter dtos.AutoFilter) bool {
+	if filter.Brand != "" && auto.Brand != "" && !strings.EqualFold(strings.TrimSpace(filter.Brand), auto.Brand) {
+		return false
+	}
+
+	if filter.Model != "" && auto.Model != "" && !strings.EqualFold(strings.TrimSpace(filter.Model), auto.Model) {
+		return false
+	}
+
+	if auto.Year > 0 {
+		if filter.MinYear != nil && *filter.MinYear > 0 && auto.Year < *filter.MinYear {
+			return false
+		}
+
+		if filter.MaxYear != nil && *filter.MaxYear > 0 && auto.Year > *filter.MaxYear {
+			return false
+		}
+	}
+
+	if filter.MinPrice != nil && *filter.MinPrice > 0 && auto.Price < *filter.MinPrice {
+		return false
+	}
+
+	if filter.MaxPrice != nil && *filter.MaxPrice > 0 && auto.Price > *filter.MaxPrice {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/fixtures"
+)
+
+func newMercadoLibreServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(fixtures.Handler(filepath.Join("testdata", "mercadolibre")))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestMercadoLibreScrapperFindByFilter(t *testing.T) {
+	srv := newMercadoLibreServer(t)
+
+	s := NewMercadoLibreScrapper("", WithBaseURL(srv.URL+"/"))
+
+	for _, tc := range neoAutoCases {
+		t.Run(tc.name, func(t *testing.T) {
+			autos, err := s.FindByFilter(context.Background(), tc.filter)
+			if err != nil {
+				t.Fatalf("FindByFilter() error = %v", err)
+			}
+
+			assertGolden(t, "mercadolibre_"+tc.name, srv, autos)
+		})
+	}
+}
+
+func TestMercadoLibreScrapperReplaysFixtures(t *testing.T) {
+	s := NewMercadoLibreScrapper("", WithFixtures(FixtureModeReplay, filepath.Join("testdata", "mercadolibre")))
+
+	autos, err := s.FindByFilter(context.Background(), dtos.AutoFilter{})
+	if err != nil {
+		t.Fatalf("FindByFilter() error = %v", err)
+	}
+
+	if len(autos) != 3 {
+		t.Errorf("FindByFilter() = %d autos, want 3", len(autos))
+	}
+}
+
+func TestMercadoLibreScrapperRateLimited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Authorization = %q, want the access token", r.Header.Get("Authorization"))
+		}
+
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message":"too many requests","status":429}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	s := NewMercadoLibreScrapper("token", WithBaseURL(srv.URL+"/"))
+
+	var blockedErr *BlockedError
+	if _, err := s.FindByFilter(context.Background(), dtos.AutoFilter{}); !errors.As(err, &blockedErr) || blockedErr.Kind != BlockRateLimited {
+		t.Errorf("FindByFilter() error = %v, want a %s block", err, BlockRateLimited)
+	}
+}
+
+func TestMercadoLibreScrapperSkipsOtherCurrencies(t *testing.T) {
+	srv := newMercadoLibreServer(t)
+	quality := NewQuality()
+
+	s := NewMercadoLibreScrapper("", WithBaseURL(srv.URL+"/"), WithQuality(quality))
+
+	// the fixture lists a Yaris for S/ 18,500, within a US$ 20,000 budget if
+	// read as dollars
+	autos, err := s.FindByFilter(context.Background(), dtos.AutoFilter{Brand: "Toyota", Model: "Yaris", MinYear: uint32Ptr(2015), MaxPrice: float64Ptr(20000)})
+	if err != nil {
+		t.Fatalf("FindByFilter() error = %v", err)
+	}
+
+	for _, auto := range autos {
+		if strings.Contains(auto.URL, "718244109") {
+			t.Errorf("FindByFilter() returned %s, priced in soles", auto.URL)
+		}
+	}
+
+	if skipped := quality.Sources()[0].Skipped[skipCurrency]; skipped != 1 {
+		t.Errorf("Skipped[%s] = %d, want 1", skipCurrency, skipped)
+	}
+}
@@ -2,280 +2,217 @@ package services
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"log"
 	"strconv"
-	"strings"
-	"time"
 
 	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/enums"
 	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
-)
-
-const (
-	neoAutoRodURL       = "https://www.neoauto.com/"
-	neoAutoRodSearchURL = neoAutoRodURL + "venta-de-autos-usados"
-	loaderImageURL      = "https://cds.neoauto.pe/neoauto3/img/loader_black.gif"
 )
 
 type NeoAutoRodScrapper struct {
-	baseURL   string
-	searchURL string
+	profiles *ProfileStore
+	options  scrapperOptions
 }
 
-func NewNeoAutoRodScrapper() *NeoAutoRodScrapper {
+func NewNeoAutoRodScrapper(profiles *ProfileStore, opts ...Option) *NeoAutoRodScrapper {
 	return &NeoAutoRodScrapper{
-		baseURL:   neoAutoRodURL,
-		searchURL: neoAutoRodSearchURL,
+		profiles: profiles,
+		options:  newScrapperOptions(opts),
 	}
 }
 
-func (s *NeoAutoRodScrapper) FindByFilter(filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
-	autos := make([]*dtos.AutoFilterResponse, 0)
-
-	// Scrape with rod
-	path, hasLauncher := launcher.LookPath()
-	if !hasLauncher {
-		return nil, errors.New("launcher not found")
-	}
+func (s *NeoAutoRodScrapper) FindByFilter(ctx context.Context, filter dtos.AutoFilter) (autos []*dtos.AutoFilterResponse, err error) {
+	autos = make([]*dtos.AutoFilterResponse, 0)
 
-	u, err := launcher.New().Headless(true).Leakless(true).Bin(path).Launch()
+	profile, err := s.profiles.Profile(enums.NeoAuto.String())
 	if err != nil {
 		return nil, err
 	}
 
-	browser := rod.New().ControlURL(u).MustConnect()
-	defer browser.MustClose()
-
-	log.Println("Generating URL")
-
-	searchURL := s.generateURL(filter)
-
-	log.Println("Searching URL", searchURL)
-
-	page := browser.MustPage(searchURL)
-	defer page.MustClose()
+	run := s.options.startRun(profile.Name, BackendRod, filter)
+	e := newExtraction()
 
-	log.Println("Waiting for cars articles...")
+	defer func() {
+		err = s.options.finishRun(run, e, len(autos), err)
+	}()
 
-	page.Race().Element("body > div.s-search > div.s-container > div.s-results.js-container.js-results-container").Handle(func(e *rod.Element) error {
-		carsArticles, err := e.Elements("article")
-		if err != nil {
-			return err
-		}
-
-		var anchorHeight float64
-
-		for _, carArticle := range carsArticles {
-			anchorSelector := "a.c-results__link"
-
-			anchor, err := carArticle.Element(anchorSelector)
-			if err != nil {
-				continue
-			}
-
-			if anchorHeight == 0 {
-				anchorHeight = anchor.MustEval(`() => this.offsetHeight`).Num()
-			}
+	searchURL, err := s.generateURL(profile, filter)
+	if err != nil {
+		return nil, err
+	}
 
-			url := anchor.MustAttribute("href")
-			if url == nil {
-				continue
-			}
+	run.URL = searchURL
 
-			contentSelector := "div.c-results__content"
+	// browsers are only launched for searches the politeness policy lets in
+	release, err := s.options.acquirePage(ctx, searchURL, profile.Politeness)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
 
-			content := carArticle.MustElement(contentSelector)
+	// Scrape with rod
+	proxy, err := s.options.nextProxy()
+	if err != nil {
+		return nil, err
+	}
 
-			title, err := s.getCarTitle(content)
-			if err != nil {
-				continue
-			}
+	run.useProxy(proxy)
 
-			resultBodySelector := "div.c-results__body"
+	fingerprint := s.options.nextFingerprint()
+	run.Fingerprint = fingerprint.Name
 
-			resultBody := content.MustElement(resultBodySelector)
+	browser, err := launchBrowser(ctx, proxy, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	defer browser.MustClose()
 
-			imageURL, err := s.getCarImageURL(resultBody)
-			if err != nil {
-				continue
-			}
+	defer func() {
+		s.options.reportProxy(proxy, err)
+	}()
 
-			// scroll based on anchor height
-			page.MustEval(`() => {
-				window.scrollBy(0, ` + strconv.FormatFloat(anchorHeight, 'f', -1, 64) + `)
-			}`)
+	page, err := newPage(browser, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	defer page.MustClose()
 
-			contactSelector := "div.c-results-details__contact"
+	console, stopConsole := s.options.captureConsole(page)
+	defer stopConsole()
 
-			contact := content.MustElement(contactSelector)
+	// runs while the page is still open
+	defer func() {
+		err = s.options.saveArtifacts(ctx, page, console, run, len(autos), err)
+	}()
 
-			price, err := s.getCarPrice(contact)
-			if err != nil {
-				continue
-			}
+	stopIntercepting := s.options.interceptRequests(page, profile, searchURL)
+	defer stopIntercepting()
 
-			autos = append(autos, &dtos.AutoFilterResponse{
-				Title:    title,
-				URL:      s.baseURL + *url,
-				ImageURL: imageURL,
-				Price:    price,
-			})
+	capture, stopCapture := captureData(page, profile.Data)
+	defer stopCapture()
 
-		}
+	stopTracking := run.trackBytes(page)
+	defer stopTracking()
 
-		return nil
-	}).MustDo()
+	// the search stops with ctx, the page is closed regardless
+	searchPage := page.Context(ctx)
 
-	log.Println("Found", len(autos), "cars")
+	run.Pages++
 
-	return autos, nil
-}
+	if err := searchPage.Navigate(searchURL); err != nil {
+		return nil, err
+	}
 
-func (s *NeoAutoRodScrapper) generateURL(filter dtos.AutoFilter) string {
-	searchURL := s.searchURL
+	container, err := searchPage.Timeout(profile.Wait.WaitTimeout()).Element(profile.ListContainer)
 
-	// Añadir filtro de marca y modelo si están especificados
-	if filter.Brand != "" {
-		// Normalizar la marca (convertir espacios en guiones, minúsculas)
-		brand := strings.ToLower(strings.ReplaceAll(filter.Brand, " ", "-"))
-		searchURL += "-" + brand
+	// listings shipped as JSON survive redesigns better than the markup
+	if found := capture.listings(page, searchURL, e); len(found) > 0 {
+		s.options.recordPage(page, searchURL)
+		s.options.recordQuality(profile, e)
 
-		if filter.Model != "" {
-			// Normalizar el modelo
-			model := strings.ToLower(strings.ReplaceAll(filter.Model, " ", "-"))
-			searchURL += "-" + model
-		}
+		return found, nil
 	}
 
-	// Construir los parámetros de consulta
-	params := make([]string, 0)
+	if err != nil {
+		// the scrape counts with no items seen, so a lost container shows up
+		s.options.recordQuality(profile, e)
 
-	if filter.MinYear != nil && *filter.MinYear > 0 {
-		params = append(params, fmt.Sprintf("anio_min=%d", *filter.MinYear))
+		return nil, missingResults(searchPage, profile, err)
 	}
 
-	if filter.MaxYear != nil && *filter.MaxYear > 0 {
-		params = append(params, fmt.Sprintf("anio_max=%d", *filter.MaxYear))
+	carsArticles, err := container.CancelTimeout().Elements(profile.Item)
+	if err != nil {
+		return nil, err
 	}
 
-	if filter.MinPrice != nil && *filter.MinPrice > 0 {
-		params = append(params, fmt.Sprintf("precio_min=%.0f", *filter.MinPrice))
-	}
+	for _, carArticle := range carsArticles {
+		e.item()
 
-	if filter.MaxPrice != nil && *filter.MaxPrice > 0 {
-		params = append(params, fmt.Sprintf("precio_max=%.0f", *filter.MaxPrice))
-	}
+		_, href, err := findField(carArticle, FieldURL, profile.Fields[FieldURL])
 
-	// Si hay parámetros, añadirlos a la URL
-	if len(params) > 0 {
-		searchURL += "?" + strings.Join(params, "&")
-	}
+		var url string
+		if err == nil {
+			url, err = profile.Fields[FieldURL].Apply(href, searchURL)
+		}
 
-	return searchURL
-}
+		if !e.require(FieldURL, err) {
+			continue
+		}
 
-func (s *NeoAutoRodScrapper) getCarTitle(carResultContent *rod.Element) (string, error) {
-	titleSelector := "div.c-results__header > h2"
+		_, title, err := findField(carArticle, FieldTitle, profile.Fields[FieldTitle])
+		if err == nil {
+			title, err = profile.Fields[FieldTitle].Apply(title, searchURL)
+		}
 
-	titleHeader, err := carResultContent.Element(titleSelector)
-	if err != nil {
-		return "", err
-	}
+		if !e.require(FieldTitle, err) {
+			continue
+		}
 
-	title, err := titleHeader.Text()
-	if err != nil {
-		return "", err
-	}
+		price, err := s.getCarPrice(carArticle, profile.Fields[FieldPrice])
+		if !e.require(FieldPrice, err) {
+			continue
+		}
 
-	return title, nil
-}
+		// the image is best effort; items are only scrolled to when it is missing
+		imageURL, err := s.getCarImageURL(ctx, carArticle, profile, searchURL)
+		e.optional(FieldImage, err)
 
-func (s *NeoAutoRodScrapper) getCarImageURL(carResultBody *rod.Element) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+		images, _ := findFieldValues(carArticle, profile.Fields[FieldImages])
+		imageURLs := listingImages(imageURL, images, profile.Fields[FieldImages], searchURL)
 
-	slidesContainer, err := carResultBody.Element("ul.glide__slides")
-	if err != nil {
-		return "", err
-	}
+		if imageURL == "" && len(imageURLs) > 0 {
+			imageURL = imageURLs[0]
+		}
 
-	imageURL, err := s.retryGetImageURL(slidesContainer, "li.glide__slide--active > a", ctx)
-	if err != nil {
-		return "", err
+		autos = append(autos, &dtos.AutoFilterResponse{
+			Title:     title,
+			URL:       url,
+			ImageURL:  imageURL,
+			ImageURLs: imageURLs,
+			Price:     price,
+		})
+		e.done()
 	}
 
-	return imageURL, nil
-}
+	s.options.recordPage(page, searchURL)
+	s.options.recordQuality(profile, e)
 
-func (s *NeoAutoRodScrapper) retryGetImageURL(element *rod.Element, selector string, ctx context.Context) (string, error) {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return "", ctx.Err()
-		case <-ticker.C:
-			imageURL, err := s.getImageURL(element, selector)
-			if err != nil {
-				continue
-			}
-
-			if imageURL == loaderImageURL {
-				continue
-			}
-
-			return imageURL, nil
-		}
-	}
+	return autos, nil
 }
-func (s *NeoAutoRodScrapper) getImageURL(element *rod.Element, selector string) (string, error) {
-	imageContainer, err := element.Element(selector)
 
-	if err != nil {
-		return "", err
-	}
+func (s *NeoAutoRodScrapper) generateURL(profile *SiteProfile, filter dtos.AutoFilter) (string, error) {
+	return profile.BuildURL(filter, s.options.baseURL, s.options.catalog)
+}
 
-	image, err := imageContainer.Element("img")
+func (s *NeoAutoRodScrapper) getCarImageURL(ctx context.Context, carArticle *rod.Element, profile *SiteProfile, pageURL string) (string, error) {
+	field := profile.Fields[FieldImage]
 
+	_, imageURL, err := findLazyField(carArticle, FieldImage, field, func() {
+		s.options.pause(ctx, profile.Politeness)
+	})
 	if err != nil {
 		return "", err
 	}
 
-	imageURL := image.MustAttribute("src")
-
-	return *imageURL, nil
+	return field.Apply(imageURL, pageURL)
 }
 
-func (s *NeoAutoRodScrapper) getCarPrice(carResultDetailContact *rod.Element) (price float64, err error) {
-	priceSelector := "div.c-results-mount__price"
-
-	textPrice := carResultDetailContact.MustElement(priceSelector).MustText()
-
-	if textPrice == "" {
-		priceSelector = "div.c-results-mount__santander-price"
-		textPrice = carResultDetailContact.MustElement(priceSelector).MustText()
+func (s *NeoAutoRodScrapper) getCarPrice(carArticle *rod.Element, field FieldSpec) (price float64, err error) {
+	_, textPrice, err := findField(carArticle, FieldPrice, field)
+	if err != nil {
+		return 0, err
 	}
 
-	price, err = s.parsePriceFromText(textPrice)
+	textPrice, err = field.Apply(textPrice, "")
 	if err != nil {
 		return 0, err
 	}
 
-	return price, nil
-
-}
-
-func (s *NeoAutoRodScrapper) parsePriceFromText(textPrice string) (price float64, err error) {
-	textPrice = strings.ReplaceAll(textPrice, ",", "")
-	splitedTextPrice := strings.Split(textPrice, " ")
-	price, err = strconv.ParseFloat(splitedTextPrice[len(splitedTextPrice)-1], 64)
+	price, err = strconv.ParseFloat(textPrice, 64)
 	if err != nil {
 		return 0, err
 	}
 
 	return price, nil
+
 }
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/enums"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/fixtures"
+	"github.com/go-rod/rod/lib/launcher"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+const neoAutoProductionURL = "https://www.neoauto.com/"
+
+func uint32Ptr(v uint32) *uint32 {
+	return &v
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+var neoAutoCases = []struct {
+	name   string
+	filter dtos.AutoFilter
+}{
+	{
+		name:   "all",
+		filter: dtos.AutoFilter{},
+	},
+	{
+		name: "toyota_yaris",
+		filter: dtos.AutoFilter{
+			Brand:    "Toyota",
+			Model:    "Yaris",
+			MinYear:  uint32Ptr(2015),
+			MaxYear:  uint32Ptr(0),
+			MinPrice: float64Ptr(0),
+			MaxPrice: float64Ptr(20000),
+		},
+	},
+}
+
+func newTestProfileStore(t *testing.T) *ProfileStore {
+	t.Helper()
+
+	profiles, err := NewProfileStore("")
+	if err != nil {
+		t.Fatalf("NewProfileStore() error = %v", err)
+	}
+
+	return profiles
+}
+
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(fixtures.Handler(filepath.Join("testdata", "neoauto")))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// assertGolden compares autos with testdata/golden/<name>.json. URLs are
+// rewritten to production so golden files do not depend on the server port.
+func assertGolden(t *testing.T, name string, srv *httptest.Server, autos []*dtos.AutoFilterResponse) {
+	t.Helper()
+
+	for _, auto := range autos {
+		auto.URL = strings.Replace(auto.URL, srv.URL+"/", neoAutoProductionURL, 1)
+	}
+
+	got, err := json.MarshalIndent(autos, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", "golden", name+".json")
+
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v", err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestNeoAutoGenerateURL(t *testing.T) {
+	profiles := newTestProfileStore(t)
+
+	profile, err := profiles.Profile(enums.NeoAuto.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filter dtos.AutoFilter
+		want   string
+	}{
+		{
+			filter: dtos.AutoFilter{},
+			want:   "https://www.neoauto.com/venta-de-autos-usados",
+		},
+		{
+			filter: dtos.AutoFilter{Model: "Yaris"},
+			want:   "https://www.neoauto.com/venta-de-autos-usados",
+		},
+		{
+			filter: dtos.AutoFilter{Brand: "Mercedes Benz", Model: "Clase C"},
+			want:   "https://www.neoauto.com/venta-de-autos-usados-mercedes-benz-clase-c",
+		},
+		{
+			filter: neoAutoCases[1].filter,
+			want:   "https://www.neoauto.com/venta-de-autos-usados-toyota-yaris?anio_min=2015&precio_max=20000",
+		},
+	}
+
+	s := NewNeoAutoRodScrapper(profiles)
+
+	for _, tt := range tests {
+		got, err := s.generateURL(profile, tt.filter)
+		if err != nil {
+			t.Fatalf("generateURL() error = %v", err)
+		}
+
+		if got != tt.want {
+			t.Errorf("generateURL() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestHTTPScrapperFindByFilter(t *testing.T) {
+	profiles := newTestProfileStore(t)
+	srv := newFixtureServer(t)
+
+	s := NewHTTPScrapper(profiles, enums.NeoAuto.String(), WithBaseURL(srv.URL+"/"))
+
+	for _, tc := range neoAutoCases {
+		t.Run(tc.name, func(t *testing.T) {
+			autos, err := s.FindByFilter(context.Background(), tc.filter)
+			if err != nil {
+				t.Fatalf("FindByFilter() error = %v", err)
+			}
+
+			assertGolden(t, "neoauto_http_"+tc.name, srv, autos)
+		})
+	}
+}
+
+func TestNeoAutoRodScrapperFindByFilter(t *testing.T) {
+	if _, ok := launcher.LookPath(); !ok {
+		t.Skip("no browser found for rod")
+	}
+
+	profiles := newTestProfileStore(t)
+	srv := newFixtureServer(t)
+
+	s := NewNeoAutoRodScrapper(profiles, WithBaseURL(srv.URL+"/"))
+
+	for _, tc := range neoAutoCases {
+		t.Run(tc.name, func(t *testing.T) {
+			autos, err := s.FindByFilter(context.Background(), tc.filter)
+			if err != nil {
+				t.Fatalf("FindByFilter() error = %v", err)
+			}
+
+			assertGolden(t, "neoauto_rod_"+tc.name, srv, autos)
+		})
+	}
+}
+
+func TestHTTPScrapperRecordReplay(t *testing.T) {
+	profiles := newTestProfileStore(t)
+	srv := newFixtureServer(t)
+	dir := t.TempDir()
+	filter := neoAutoCases[1].filter
+
+	recorder := NewHTTPScrapper(profiles, enums.NeoAuto.String(), WithBaseURL(srv.URL+"/"), WithFixtures(FixtureModeRecord, dir))
+
+	recorded, err := recorder.FindByFilter(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("record FindByFilter() error = %v", err)
+	}
+
+	// replay must not touch the network
+	srv.Close()
+
+	player := NewHTTPScrapper(profiles, enums.NeoAuto.String(), WithBaseURL(srv.URL+"/"), WithFixtures(FixtureModeReplay, dir))
+
+	replayed, err := player.FindByFilter(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("replay FindByFilter() error = %v", err)
+	}
+
+	got, _ := json.Marshal(replayed)
+	want, _ := json.Marshal(recorded)
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("replayed %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// scrapperOptions are the settings shared by the scrappers.
+type scrapperOptions struct {
+	baseURL      string
+	fixtureMode  string
+	fixtureDir   string
+	politeness   *Politeness
+	proxies      *ProxyPool
+	cooldowns    *Cooldowns
+	fingerprints *FingerprintPool
+	quality      *Quality
+	artifacts    ArtifactStore
+	runs         RunLog
+	catalog      *Catalog
+}
+
+// Option configures a scrapper.
+type Option func(*scrapperOptions)
+
+// WithBaseURL overrides the site profile base URL, for example to scrape a
+// local fixture server.
+func WithBaseURL(baseURL string) Option {
+	return func(o *scrapperOptions) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithPoliteness enforces the politeness policies of the site profiles
+// through politeness, which should be shared by all the scrappers.
+func WithPoliteness(politeness *Politeness) Option {
+	return func(o *scrapperOptions) {
+		o.politeness = politeness
+	}
+}
+
+// WithProxyPool sends the scrapper traffic through the proxies of pool.
+func WithProxyPool(pool *ProxyPool) Option {
+	return func(o *scrapperOptions) {
+		o.proxies = pool
+	}
+}
+
+func newScrapperOptions(opts []Option) scrapperOptions {
+	var options scrapperOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+// acquirePage waits until the politeness policy allows opening pageURL. The
+// returned function must be called once the page is done.
+func (o scrapperOptions) acquirePage(ctx context.Context, pageURL string, policy PolitenessPolicy) (func(), error) {
+	if o.politeness == nil || o.fixtureMode == FixtureModeReplay {
+		return func() {}, nil
+	}
+
+	return o.politeness.Acquire(ctx, pageURL, policy)
+}
+
+// navigateTo waits until the politeness policy allows navigating an already
+// acquired page to pageURL.
+func (o scrapperOptions) navigateTo(ctx context.Context, pageURL string, policy PolitenessPolicy) error {
+	if o.politeness == nil || o.fixtureMode == FixtureModeReplay {
+		return nil
+	}
+
+	policy.Pause(ctx)
+
+	return o.politeness.Wait(ctx, pageURL, policy)
+}
+
+// pause waits a random delay between two steps on the same page.
+func (o scrapperOptions) pause(ctx context.Context, policy PolitenessPolicy) {
+	if o.politeness == nil || o.fixtureMode == FixtureModeReplay {
+		return
+	}
+
+	policy.Pause(ctx)
+}
+
+// nextProxy returns the proxy for a new session or request, or nil when
+// scraping without proxies.
+func (o scrapperOptions) nextProxy() (*Proxy, error) {
+	if o.proxies == nil || o.fixtureMode == FixtureModeReplay {
+		return nil, nil
+	}
+
+	return o.proxies.Next()
+}
+
+// reportProxy scores proxy with the result of the request made through it.
+func (o scrapperOptions) reportProxy(proxy *Proxy, err error) {
+	if o.proxies == nil || proxy == nil {
+		return
+	}
+
+	switch {
+	case err == nil:
+		o.proxies.Report(proxy, ProxySucceeded)
+	case errors.Is(err, ErrBlocked), isBlockedStatus(err):
+		o.proxies.Report(proxy, ProxyBlocked)
+	default:
+		o.proxies.Report(proxy, ProxyFailed)
+	}
+}
+
+// send sends req with the scrapper user agent, through the next proxy when
+// scraping with proxies, and reports the proxy.
+func (o scrapperOptions) send(client *http.Client, req *http.Request) (*http.Response, error) {
+	proxy, err := o.nextProxy()
+	if err != nil {
+		return nil, err
+	}
+
+	if proxy != nil {
+		client = &http.Client{Timeout: client.Timeout, Transport: proxy.Transport}
+	}
+
+	req.Header.Set("User-Agent", httpUserAgent)
+
+	resp, err := client.Do(req)
+	o.reportProxy(proxy, err)
+
+	return resp, err
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultRequestsPerMinute  = 20
+	defaultBurst              = 2
+	defaultMaxConcurrentPages = 2
+	defaultMinDelay           = 500 * time.Millisecond
+	defaultMaxDelay           = 1500 * time.Millisecond
+
+	robotsUserAgent = "AutoRadar"
+	robotsTTL       = time.Hour
+	robotsTimeout   = 10 * time.Second
+)
+
+// ErrDisallowedByRobots is returned when robots.txt forbids a page.
+var ErrDisallowedByRobots = errors.New("disallowed by robots.txt")
+
+// PolitenessPolicy limits how hard a source is hit. Zero values use the
+// defaults. IgnoreRobots skips robots.txt for sources we have permission to
+// scrape.
+type PolitenessPolicy struct {
+	RequestsPerMinute  float64       `yaml:"requests_per_minute"`
+	Burst              int           `yaml:"burst"`
+	MaxConcurrentPages int           `yaml:"max_concurrent_pages"`
+	MinDelay           time.Duration `yaml:"min_delay"`
+	MaxDelay           time.Duration `yaml:"max_delay"`
+	IgnoreRobots       bool          `yaml:"ignore_robots"`
+}
+
+func (p PolitenessPolicy) limit() rate.Limit {
+	if p.RequestsPerMinute <= 0 {
+		return rate.Limit(defaultRequestsPerMinute / 60.0)
+	}
+
+	return rate.Limit(p.RequestsPerMinute / 60)
+}
+
+func (p PolitenessPolicy) burst() int {
+	if p.Burst <= 0 {
+		return defaultBurst
+	}
+
+	return p.Burst
+}
+
+func (p PolitenessPolicy) maxConcurrentPages() int {
+	if p.MaxConcurrentPages <= 0 {
+		return defaultMaxConcurrentPages
+	}
+
+	return p.MaxConcurrentPages
+}
+
+// Pause sleeps a random delay between MinDelay and MaxDelay, to space out
+// scroll and navigation steps like a person would.
+func (p PolitenessPolicy) Pause(ctx context.Context) {
+	minDelay, maxDelay := p.MinDelay, p.MaxDelay
+	if minDelay == 0 && maxDelay == 0 {
+		minDelay, maxDelay = defaultMinDelay, defaultMaxDelay
+	}
+
+	delay := minDelay
+	if maxDelay > minDelay {
+		delay += time.Duration(rand.Int63n(int64(maxDelay - minDelay)))
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// Politeness enforces the politeness policies of every source per target
+// host. A single instance is shared by all the scrappers of the process, so
+// every caller of a host shares its budget, under the strictest policy any of
+// them asked for.
+type Politeness struct {
+	client *http.Client
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// hostState is the budget of a host. Its limits only tighten, until the
+// process restarts, so no policy ever hits a host harder than another one
+// sharing it allows. Pages are counted rather than held in a channel, so a
+// stricter policy applies to the pages already held too.
+type hostState struct {
+	limiter *rate.Limiter
+
+	pages    int
+	held     int
+	released chan struct{}
+
+	robots        *robotstxt.RobotsData
+	robotsFetched time.Time
+}
+
+func NewPoliteness() *Politeness {
+	return &Politeness{
+		client: &http.Client{Timeout: robotsTimeout},
+		hosts:  make(map[string]*hostState),
+	}
+}
+
+// Acquire waits for a free page slot and a request token for the host of
+// pageURL, after checking robots.txt. The returned function frees the slot.
+func (p *Politeness) Acquire(ctx context.Context, pageURL string, policy PolitenessPolicy) (func(), error) {
+	u, host, err := p.host(pageURL, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if !policy.IgnoreRobots {
+		if err := p.checkRobots(ctx, u, host); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.acquirePage(ctx, host); err != nil {
+		return nil, err
+	}
+
+	release := func() {
+		p.releasePage(host)
+	}
+
+	if err := host.limiter.Wait(ctx); err != nil {
+		release()
+		return nil, err
+	}
+
+	return release, nil
+}
+
+// Wait waits for a request token before another navigation on a page slot
+// already acquired, and checks robots.txt for the new page.
+func (p *Politeness) Wait(ctx context.Context, pageURL string, policy PolitenessPolicy) error {
+	u, host, err := p.host(pageURL, policy)
+	if err != nil {
+		return err
+	}
+
+	if !policy.IgnoreRobots {
+		if err := p.checkRobots(ctx, u, host); err != nil {
+			return err
+		}
+	}
+
+	return host.limiter.Wait(ctx)
+}
+
+// host returns the state of the host of pageURL, tightened to the limits of
+// policy.
+func (p *Politeness) host(pageURL string, policy PolitenessPolicy) (*url.URL, *hostState, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	host, ok := p.hosts[u.Host]
+	if !ok {
+		host = &hostState{
+			limiter:  rate.NewLimiter(policy.limit(), policy.burst()),
+			pages:    policy.maxConcurrentPages(),
+			released: make(chan struct{}),
+		}
+		p.hosts[u.Host] = host
+
+		return u, host, nil
+	}
+
+	if limit := policy.limit(); limit < host.limiter.Limit() {
+		host.limiter.SetLimit(limit)
+	}
+
+	if burst := policy.burst(); burst < host.limiter.Burst() {
+		host.limiter.SetBurst(burst)
+	}
+
+	host.pages = min(host.pages, policy.maxConcurrentPages())
+
+	return u, host, nil
+}
+
+// acquirePage waits until fewer pages than allowed are open on host.
+func (p *Politeness) acquirePage(ctx context.Context, host *hostState) error {
+	for {
+		p.mu.Lock()
+		if host.held < host.pages {
+			host.held++
+			p.mu.Unlock()
+
+			return nil
+		}
+
+		released := host.released
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+func (p *Politeness) releasePage(host *hostState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	host.held--
+
+	// wakes every waiter, which compete for the freed slot
+	close(host.released)
+	host.released = make(chan struct{})
+}
+
+func (p *Politeness) checkRobots(ctx context.Context, u *url.URL, host *hostState) error {
+	p.mu.Lock()
+	robots := host.robots
+	fresh := time.Since(host.robotsFetched) < robotsTTL
+	p.mu.Unlock()
+
+	if robots == nil || !fresh {
+		fetched, err := p.fetchRobots(ctx, u)
+		if err != nil {
+			// robots.txt being unreachable does not forbid scraping
+			log.Println("robots.txt unavailable for", u.Host, err)
+			return nil
+		}
+
+		p.mu.Lock()
+		host.robots = fetched
+		host.robotsFetched = time.Now()
+		p.mu.Unlock()
+
+		robots = fetched
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	if !robots.TestAgent(path, robotsUserAgent) {
+		return fmt.Errorf("%w: %s", ErrDisallowedByRobots, u)
+	}
+
+	return nil
+}
+
+func (p *Politeness) fetchRobots(ctx context.Context, u *url.URL) (*robotstxt.RobotsData, error) {
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", httpUserAgent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return robotstxt.FromStatusAndBytes(resp.StatusCode, body)
+}
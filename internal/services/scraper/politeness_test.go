@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newRobotsServer(t *testing.T, robots string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, robots)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestPolitenessRobots(t *testing.T) {
+	srv := newRobotsServer(t, "User-agent: *\nDisallow: /private\n")
+	policy := PolitenessPolicy{RequestsPerMinute: 6000, Burst: 10}
+
+	p := NewPoliteness()
+	ctx := context.Background()
+
+	release, err := p.Acquire(ctx, srv.URL+"/public", policy)
+	if err != nil {
+		t.Fatalf("Acquire(/public) error = %v", err)
+	}
+	release()
+
+	if _, err := p.Acquire(ctx, srv.URL+"/private/1", policy); !errors.Is(err, ErrDisallowedByRobots) {
+		t.Fatalf("Acquire(/private/1) error = %v, want %v", err, ErrDisallowedByRobots)
+	}
+
+	policy.IgnoreRobots = true
+
+	release, err = p.Acquire(ctx, srv.URL+"/private/1", policy)
+	if err != nil {
+		t.Fatalf("Acquire(/private/1) ignoring robots error = %v", err)
+	}
+	release()
+}
+
+func TestPolitenessMaxConcurrentPages(t *testing.T) {
+	srv := newRobotsServer(t, "")
+	policy := PolitenessPolicy{RequestsPerMinute: 6000, Burst: 10, MaxConcurrentPages: 1}
+
+	p := NewPoliteness()
+
+	release, err := p.Acquire(context.Background(), srv.URL+"/a", policy)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.Acquire(ctx, srv.URL+"/b", policy); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second Acquire() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	release()
+
+	release, err = p.Acquire(context.Background(), srv.URL+"/b", policy)
+	if err != nil {
+		t.Fatalf("Acquire() after release error = %v", err)
+	}
+	release()
+}
+
+func TestPolitenessSharesHostBudget(t *testing.T) {
+	srv := newRobotsServer(t, "")
+	single := PolitenessPolicy{RequestsPerMinute: 6000, Burst: 10, MaxConcurrentPages: 1}
+	double := PolitenessPolicy{RequestsPerMinute: 12000, Burst: 20, MaxConcurrentPages: 2}
+
+	p := NewPoliteness()
+
+	release, err := p.Acquire(context.Background(), srv.URL+"/a", double)
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+
+	other, err := p.Acquire(context.Background(), srv.URL+"/b", double)
+	if err != nil {
+		t.Fatalf("second Acquire() error = %v", err)
+	}
+
+	// a stricter policy for the host lowers its budget for every caller
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.Acquire(ctx, srv.URL+"/c", single); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire() with the stricter policy error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	release()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := p.Acquire(ctx, srv.URL+"/c", double); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire() with the looser policy error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	other()
+
+	last, err := p.Acquire(context.Background(), srv.URL+"/c", double)
+	if err != nil {
+		t.Fatalf("Acquire() after releasing both pages error = %v", err)
+	}
+	last()
+
+	host := p.hosts[strings.TrimPrefix(srv.URL, "http://")]
+	if host.limiter.Limit() != single.limit() || host.limiter.Burst() != single.burst() {
+		t.Errorf("host limits = %v, %d, want those of the stricter policy", host.limiter.Limit(), host.limiter.Burst())
+	}
+}
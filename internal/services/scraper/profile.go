@@ -0,0 +1,695 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"gopkg.in/yaml.v3"
+)
+
+// Field names every site profile must declare.
+const (
+	FieldURL   = "url"
+	FieldTitle = "title"
+	FieldImage = "image"
+	FieldPrice = "price"
+)
+
+var requiredFields = []string{FieldURL, FieldTitle, FieldImage, FieldPrice}
+
+// FieldImages optionally lists every image of a listing, e.g. all the slides
+// of its gallery. Unlike the other fields it keeps the values of every
+// element its selectors match.
+const FieldImages = "images"
+
+// Pagination strategies supported by site profiles.
+const (
+	PaginationNone     = "none"
+	PaginationQuery    = "query"
+	PaginationNextLink = "next_link"
+	PaginationScroll   = "scroll"
+)
+
+// Backends able to scrape a site profile. The http backend falls back to rod
+// when the static HTML has no results.
+const (
+	BackendRod  = "rod"
+	BackendHTTP = "http"
+)
+
+const defaultWaitTimeout = 30 * time.Second
+
+// SiteProfile declares how to build a search URL for a marketplace and where
+// each listing field lives in its markup. Profiles are plain YAML or JSON so
+// markup changes only need a configuration update.
+type SiteProfile struct {
+	Name          string               `yaml:"name"`
+	BaseURL       string               `yaml:"base_url"`
+	Backend       string               `yaml:"backend"`
+	Search        SearchSpec           `yaml:"search"`
+	ListContainer string               `yaml:"list_container"`
+	Item          string               `yaml:"item"`
+	Fields        map[string]FieldSpec `yaml:"fields"`
+	Data          DataSpec             `yaml:"data"`
+	Pagination    PaginationSpec       `yaml:"pagination"`
+	Wait          WaitSpec             `yaml:"wait"`
+	Politeness    PolitenessPolicy     `yaml:"politeness"`
+
+	// BlockSignatures recognize the block pages of the site, on top of the
+	// common challenge, captcha and access denied pages.
+	BlockSignatures []BlockSignature `yaml:"block_signatures"`
+
+	// Resources are the requests browser pages skip to load faster.
+	Resources ResourcePolicy `yaml:"block_resources"`
+
+	// Quality is when the extraction ratio of the source raises an alert.
+	Quality QualityPolicy `yaml:"quality"`
+
+	searchTemplate *template.Template
+	queryTemplates []*template.Template
+}
+
+// SearchSpec builds the search URL. Template renders the URL without query
+// string; each Query entry is rendered in order and skipped when empty.
+type SearchSpec struct {
+	Template string       `yaml:"template"`
+	Query    []QueryParam `yaml:"query"`
+}
+
+// QueryParam is either a Value template or a rule mapping a filter Field
+// (brand, model, min_year, max_year, min_price, max_price) to the parameter,
+// printed with Format ("%v" by default).
+type QueryParam struct {
+	Name   string `yaml:"name"`
+	Value  string `yaml:"value"`
+	Field  string `yaml:"field"`
+	Format string `yaml:"format"`
+}
+
+// PaginationSpec tells the scraper how to reach the following result pages.
+// The query strategy sets Param to Start, Start+1, ...; next_link follows
+// the href of NextSelector; scroll scrolls to the bottom of the page to load
+// more items. At most MaxPages pages are visited.
+type PaginationSpec struct {
+	Strategy     string `yaml:"strategy"`
+	Param        string `yaml:"param"`
+	Start        int    `yaml:"start"`
+	NextSelector string `yaml:"next_selector"`
+	MaxPages     int    `yaml:"max_pages"`
+}
+
+// WaitSpec describes when a results page is ready to be scraped: Selector
+// (the list container by default) must appear within Timeout, and the page
+// optionally waits for network Idle and for the DOM to be Stable.
+type WaitSpec struct {
+	Selector string        `yaml:"selector"`
+	Timeout  time.Duration `yaml:"timeout"`
+	Idle     bool          `yaml:"idle"`
+	Stable   time.Duration `yaml:"stable"`
+}
+
+// FieldSpec locates a field inside a listing item. Selectors are relative to
+// the item and tried in order until one yields a value. Extract is either
+// "text" (the default) or "attr:<name>[,<name>...]", trying each attribute in
+// order; srcset attributes yield their widest image. With JSON the extracted
+// value is parsed as JSON and the strings at that dotted path are used, arrays
+// being walked through, e.g. "image.url" or "." for the whole document.
+// Values equal to Placeholder are treated as not loaded yet. Transforms are
+// applied in order to the extracted value: "trim", "regex:<pattern>" (keeps
+// the first group or the whole match), "price" (keeps the last number without
+// thousands separators) and "absolute_url".
+type FieldSpec struct {
+	Selectors   []string `yaml:"selectors"`
+	Extract     string   `yaml:"extract"`
+	Placeholder string   `yaml:"placeholder"`
+	JSON        string   `yaml:"json"`
+	Transforms  []string `yaml:"transforms"`
+
+	transforms []transform
+}
+
+// transform rewrites an extracted value. base is the URL the value was
+// scraped from.
+type transform func(value string, base *url.URL) (string, error)
+
+var priceNumberPattern = regexp.MustCompile(`[0-9][0-9.,]*`)
+
+// filterFields maps the filter field names used in query rules to the
+// searchParams field holding their value.
+var filterFields = map[string]string{
+	"brand":     "Brand",
+	"model":     "Model",
+	"min_year":  "MinYear",
+	"max_year":  "MaxYear",
+	"min_price": "MinPrice",
+	"max_price": "MaxPrice",
+}
+
+// searchParams is the data passed to the search URL templates. Brand and
+// Model are the catalog names when they resolve, and BrandSlug and ModelSlug
+// their slugs for the source.
+type searchParams struct {
+	BaseURL   string
+	Brand     string
+	Model     string
+	BrandSlug string
+	ModelSlug string
+	MinYear   uint32
+	MaxYear   uint32
+	MinPrice  float64
+	MaxPrice  float64
+}
+
+var templateFuncs = template.FuncMap{
+	"slug": slug,
+}
+
+// ParseSiteProfile decodes a YAML or JSON profile and validates it.
+func ParseSiteProfile(data []byte) (*SiteProfile, error) {
+	var profile SiteProfile
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&profile); err != nil {
+		return nil, err
+	}
+
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// Validate checks the profile and compiles its URL templates.
+func (p *SiteProfile) Validate() error {
+	var errs []error
+
+	if p.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+
+	if _, err := url.ParseRequestURI(p.BaseURL); err != nil {
+		errs = append(errs, fmt.Errorf("base_url: %w", err))
+	}
+
+	if p.ListContainer == "" {
+		errs = append(errs, errors.New("list_container is required"))
+	}
+
+	if p.Item == "" {
+		errs = append(errs, errors.New("item is required"))
+	}
+
+	searchTemplate, err := template.New("search").Funcs(templateFuncs).Parse(p.Search.Template)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("search.template: %w", err))
+	} else if p.Search.Template == "" {
+		errs = append(errs, errors.New("search.template is required"))
+	}
+
+	queryTemplates := make([]*template.Template, 0, len(p.Search.Query))
+	for i, param := range p.Search.Query {
+		if param.Name == "" {
+			errs = append(errs, fmt.Errorf("search.query[%d]: name is required", i))
+		}
+
+		if param.Field != "" {
+			if param.Value != "" {
+				errs = append(errs, fmt.Errorf("search.query[%d]: value and field are exclusive", i))
+			}
+
+			if _, ok := filterFields[param.Field]; !ok {
+				errs = append(errs, fmt.Errorf("search.query[%d]: unknown field %q", i, param.Field))
+				continue
+			}
+
+			format := param.Format
+			if format == "" {
+				format = "%v"
+			}
+
+			param.Value = fmt.Sprintf(`{{if .%s}}{{printf %q .%s}}{{end}}`, filterFields[param.Field], format, filterFields[param.Field])
+		}
+
+		queryTemplate, err := template.New(param.Name).Funcs(templateFuncs).Parse(param.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("search.query[%d]: %w", i, err))
+			continue
+		}
+
+		queryTemplates = append(queryTemplates, queryTemplate)
+	}
+
+	for _, name := range requiredFields {
+		if _, ok := p.Fields[name]; !ok {
+			errs = append(errs, fmt.Errorf("fields.%s is required", name))
+		}
+	}
+
+	for name, field := range p.Fields {
+		if len(field.Selectors) == 0 {
+			errs = append(errs, fmt.Errorf("fields.%s: at least one selector is required", name))
+		}
+
+		if _, _, err := field.extractor(); err != nil {
+			errs = append(errs, fmt.Errorf("fields.%s: %w", name, err))
+		}
+
+		transforms, err := compileTransforms(field.Transforms)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fields.%s: %w", name, err))
+		}
+
+		field.transforms = transforms
+		p.Fields[name] = field
+	}
+
+	switch p.Backend {
+	case "", BackendRod, BackendHTTP:
+	default:
+		errs = append(errs, fmt.Errorf("backend: unknown backend %q", p.Backend))
+	}
+
+	switch p.Pagination.Strategy {
+	case "", PaginationNone, PaginationScroll:
+	case PaginationQuery:
+		if p.Pagination.Param == "" {
+			errs = append(errs, errors.New("pagination.param is required for the query strategy"))
+		}
+	case PaginationNextLink:
+		if p.Pagination.NextSelector == "" {
+			errs = append(errs, errors.New("pagination.next_selector is required for the next_link strategy"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("pagination.strategy: unknown strategy %q", p.Pagination.Strategy))
+	}
+
+	if p.Pagination.MaxPages < 0 {
+		errs = append(errs, errors.New("pagination.max_pages must not be negative"))
+	}
+
+	if p.Wait.Timeout < 0 || p.Wait.Stable < 0 {
+		errs = append(errs, errors.New("wait durations must not be negative"))
+	}
+
+	if p.Politeness.MinDelay < 0 || p.Politeness.MaxDelay < p.Politeness.MinDelay {
+		errs = append(errs, errors.New("politeness: delays must satisfy 0 <= min_delay <= max_delay"))
+	}
+
+	if err := p.Data.compile(); err != nil {
+		errs = append(errs, fmt.Errorf("data: %w", err))
+	}
+
+	if err := p.Resources.compile(); err != nil {
+		errs = append(errs, fmt.Errorf("block_resources: %w", err))
+	}
+
+	for i := range p.BlockSignatures {
+		if err := p.BlockSignatures[i].compile(); err != nil {
+			errs = append(errs, fmt.Errorf("block_signatures[%d]: %w", i, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("profile %q: %w", p.Name, errors.Join(errs...))
+	}
+
+	p.searchTemplate = searchTemplate
+	p.queryTemplates = queryTemplates
+
+	return nil
+}
+
+// BuildURL renders the search URL for the filter. baseURL overrides the
+// profile base URL when not empty. The brand and model are resolved in
+// catalog, which may be nil.
+func (p *SiteProfile) BuildURL(filter dtos.AutoFilter, baseURL string, catalog *Catalog) (string, error) {
+	if baseURL == "" {
+		baseURL = p.BaseURL
+	}
+
+	params := searchParams{
+		BaseURL:   baseURL,
+		Brand:     filter.Brand,
+		Model:     filter.Model,
+		BrandSlug: slug(filter.Brand),
+		ModelSlug: slug(filter.Model),
+	}
+
+	if match, ok := catalog.Resolve(filter.Brand, filter.Model); ok && filter.Brand != "" {
+		params.Brand = match.Brand.Name
+		params.BrandSlug = match.Brand.Slug(p.Name)
+
+		if match.Model != nil {
+			params.Model = match.Model.Name
+			params.ModelSlug = match.Model.Slug(p.Name)
+		}
+	}
+
+	if filter.MinYear != nil {
+		params.MinYear = *filter.MinYear
+	}
+
+	if filter.MaxYear != nil {
+		params.MaxYear = *filter.MaxYear
+	}
+
+	if filter.MinPrice != nil {
+		params.MinPrice = *filter.MinPrice
+	}
+
+	if filter.MaxPrice != nil {
+		params.MaxPrice = *filter.MaxPrice
+	}
+
+	var searchURL strings.Builder
+	if err := p.searchTemplate.Execute(&searchURL, params); err != nil {
+		return "", err
+	}
+
+	query := make([]string, 0, len(p.queryTemplates))
+
+	for i, queryTemplate := range p.queryTemplates {
+		var value strings.Builder
+		if err := queryTemplate.Execute(&value, params); err != nil {
+			return "", err
+		}
+
+		if value.Len() == 0 {
+			continue
+		}
+
+		query = append(query, p.Search.Query[i].Name+"="+url.QueryEscape(value.String()))
+	}
+
+	if len(query) > 0 {
+		return searchURL.String() + "?" + strings.Join(query, "&"), nil
+	}
+
+	return searchURL.String(), nil
+}
+
+// AbsoluteURL resolves ref against the profile base URL.
+func (p *SiteProfile) AbsoluteURL(ref string, baseURL string) (string, error) {
+	if baseURL == "" {
+		baseURL = p.BaseURL
+	}
+
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return base.ResolveReference(target).String(), nil
+}
+
+// Pages returns how many result pages should be visited.
+func (p PaginationSpec) Pages() int {
+	if p.Strategy == "" || p.Strategy == PaginationNone || p.MaxPages == 0 {
+		return 1
+	}
+
+	return p.MaxPages
+}
+
+// PageURL returns the URL of the page with the given zero based index for
+// the query strategy.
+func (p PaginationSpec) PageURL(searchURL string, index int) (string, error) {
+	pageURL, err := url.Parse(searchURL)
+	if err != nil {
+		return "", err
+	}
+
+	start := p.Start
+	if start == 0 {
+		start = 1
+	}
+
+	query := pageURL.Query()
+	query.Set(p.Param, fmt.Sprint(start+index))
+	pageURL.RawQuery = query.Encode()
+
+	return pageURL.String(), nil
+}
+
+// WaitTimeout returns the configured wait timeout or the default one.
+func (w WaitSpec) WaitTimeout() time.Duration {
+	if w.Timeout == 0 {
+		return defaultWaitTimeout
+	}
+
+	return w.Timeout
+}
+
+// Apply runs the field transforms over value. pageURL resolves relative URLs.
+func (f FieldSpec) Apply(value string, pageURL string) (string, error) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return "", err
+	}
+
+	for _, transform := range f.transforms {
+		value, err = transform(value, base)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return value, nil
+}
+
+func compileTransforms(names []string) ([]transform, error) {
+	transforms := make([]transform, 0, len(names))
+
+	for _, name := range names {
+		switch {
+		case name == "trim":
+			transforms = append(transforms, func(value string, _ *url.URL) (string, error) {
+				return strings.Join(strings.Fields(value), " "), nil
+			})
+		case name == "price":
+			transforms = append(transforms, func(value string, _ *url.URL) (string, error) {
+				numbers := priceNumberPattern.FindAllString(value, -1)
+				if len(numbers) == 0 {
+					return "", fmt.Errorf("no price in %q", value)
+				}
+
+				return strings.ReplaceAll(numbers[len(numbers)-1], ",", ""), nil
+			})
+		case name == "absolute_url":
+			transforms = append(transforms, func(value string, base *url.URL) (string, error) {
+				target, err := url.Parse(value)
+				if err != nil {
+					return "", err
+				}
+
+				return base.ResolveReference(target).String(), nil
+			})
+		case strings.HasPrefix(name, "regex:"):
+			pattern, err := regexp.Compile(strings.TrimPrefix(name, "regex:"))
+			if err != nil {
+				return nil, err
+			}
+
+			transforms = append(transforms, func(value string, _ *url.URL) (string, error) {
+				match := pattern.FindStringSubmatch(value)
+				if match == nil {
+					return "", fmt.Errorf("%q does not match %s", value, pattern)
+				}
+
+				return match[len(match)-1], nil
+			})
+		default:
+			return nil, fmt.Errorf("unknown transform %q", name)
+		}
+	}
+
+	return transforms, nil
+}
+
+// extractor returns the extraction kind ("text" or "attr") and, for
+// attributes, the attribute name.
+func (f FieldSpec) extractor() (kind string, attributes []string, err error) {
+	switch {
+	case f.Extract == "" || f.Extract == "text":
+		return "text", nil, nil
+	case strings.HasPrefix(f.Extract, "attr:"):
+		for _, attribute := range strings.Split(strings.TrimPrefix(f.Extract, "attr:"), ",") {
+			attribute = strings.TrimSpace(attribute)
+			if attribute == "" {
+				return "", nil, fmt.Errorf("unknown extract %q", f.Extract)
+			}
+
+			attributes = append(attributes, attribute)
+		}
+
+		return "attr", attributes, nil
+	default:
+		return "", nil, fmt.Errorf("unknown extract %q", f.Extract)
+	}
+}
+
+// values returns the usable values of an extracted value: the strings at the
+// JSON path when set, the value itself otherwise, without empty values and
+// placeholders.
+func (f FieldSpec) values(value string) []string {
+	if f.JSON == "" {
+		return f.usable([]string{value})
+	}
+
+	var document any
+	if err := json.Unmarshal([]byte(value), &document); err != nil {
+		return nil
+	}
+
+	return f.nodeValues(document)
+}
+
+// nodeValues returns the usable values at the JSON path of node.
+func (f FieldSpec) nodeValues(node any) []string {
+	var candidates []string
+
+	for _, leaf := range jsonLookup(node, jsonPath(f.JSON)) {
+		switch leaf := leaf.(type) {
+		case string:
+			candidates = append(candidates, leaf)
+		case float64:
+			candidates = append(candidates, strconv.FormatFloat(leaf, 'f', -1, 64))
+		}
+	}
+
+	return f.usable(candidates)
+}
+
+func (f FieldSpec) usable(candidates []string) []string {
+	values := make([]string, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		if candidate = strings.TrimSpace(candidate); candidate != "" && candidate != f.Placeholder {
+			values = append(values, candidate)
+		}
+	}
+
+	return values
+}
+
+func jsonPath(path string) []string {
+	return strings.FieldsFunc(path, func(r rune) bool { return r == '.' })
+}
+
+// jsonLookup returns the nodes at path in node, walking through arrays.
+func jsonLookup(node any, path []string) []any {
+	switch node := node.(type) {
+	case []any:
+		var nodes []any
+		for _, element := range node {
+			nodes = append(nodes, jsonLookup(element, path)...)
+		}
+
+		return nodes
+	case map[string]any:
+		if len(path) == 0 {
+			return []any{node}
+		}
+
+		return jsonLookup(node[path[0]], path[1:])
+	case nil:
+		return nil
+	}
+
+	if len(path) > 0 {
+		return nil
+	}
+
+	return []any{node}
+}
+
+// listingImages returns the images of a listing, imageURL first and the
+// values transformed by field, without duplicates.
+func listingImages(imageURL string, values []string, field FieldSpec, pageURL string) []string {
+	images := make([]string, 0, len(values)+1)
+	seen := make(map[string]bool)
+
+	if imageURL != "" {
+		images = append(images, imageURL)
+		seen[imageURL] = true
+	}
+
+	for _, value := range values {
+		image, err := field.Apply(value, pageURL)
+		if err != nil || image == "" || seen[image] {
+			continue
+		}
+
+		seen[image] = true
+		images = append(images, image)
+	}
+
+	return images
+}
+
+// attributeValue returns the value of attribute, or "" when it is empty or
+// the placeholder.
+func (f FieldSpec) attributeValue(attribute string, value string) string {
+	if attribute == "srcset" || strings.HasSuffix(attribute, "-srcset") {
+		value = widestSrcset(value)
+	}
+
+	if value == f.Placeholder {
+		return ""
+	}
+
+	return strings.TrimSpace(value)
+}
+
+// widestSrcset returns the candidate URL of a srcset with the largest width
+// or density descriptor, the first one when none has a descriptor.
+func widestSrcset(srcset string) string {
+	var widest string
+	var widestSize float64
+
+	for _, candidate := range strings.Split(srcset, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var size float64
+		if len(fields) > 1 {
+			size, _ = strconv.ParseFloat(strings.TrimRight(fields[1], "wx"), 64)
+		}
+
+		if widest == "" || size > widestSize {
+			widest, widestSize = fields[0], size
+		}
+	}
+
+	return widest
+}
+
+// slug lowercases s, drops its accents and joins its words with hyphens, the
+// format most marketplaces use in their search paths.
+func slug(s string) string {
+	words := strings.FieldsFunc(foldAccents(strings.ToLower(s)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, "-")
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// lazyLoadWait is how long a lazy loader gets to fill an item scrolled into
+// view.
+const lazyLoadWait = 500 * time.Millisecond
+
+// findField returns the first element matched by the field selectors inside
+// item together with its extracted value. Missing elements are not waited for.
+func findField(item *rod.Element, name string, field FieldSpec) (*rod.Element, string, error) {
+	for _, selector := range field.Selectors {
+		found, element, err := item.Has(selector)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if !found {
+			continue
+		}
+
+		value, err := extractValue(element, field)
+		if err != nil {
+			return nil, "", err
+		}
+
+		values := field.values(value)
+		if len(values) == 0 {
+			continue
+		}
+
+		return element, values[0], nil
+	}
+
+	return nil, "", fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+}
+
+// findFieldValues returns the values of every element matched by the field
+// selectors inside item.
+func findFieldValues(item *rod.Element, field FieldSpec) ([]string, error) {
+	var values []string
+
+	for _, selector := range field.Selectors {
+		elements, err := item.Elements(selector)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, element := range elements {
+			value, err := extractValue(element, field)
+			if err != nil {
+				return nil, err
+			}
+
+			values = append(values, field.values(value)...)
+		}
+	}
+
+	return values, nil
+}
+
+// findLazyField is findField with scrolling as a fallback, for lazy loaders
+// that only fill the field once item is visible. pause spaces out the
+// scrolls; the loader gets at least lazyLoadWait either way.
+func findLazyField(item *rod.Element, name string, field FieldSpec, pause func()) (*rod.Element, string, error) {
+	element, value, err := findField(item, name, field)
+	if err == nil {
+		return element, value, nil
+	}
+
+	if err := item.ScrollIntoView(); err != nil {
+		return nil, "", err
+	}
+
+	start := time.Now()
+	pause()
+
+	if wait := lazyLoadWait - time.Since(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+
+		select {
+		case <-item.GetContext().Done():
+			return nil, "", item.GetContext().Err()
+		case <-timer.C:
+		}
+	}
+
+	return findField(item, name, field)
+}
+
+func extractValue(element *rod.Element, field FieldSpec) (string, error) {
+	kind, attributes, err := field.extractor()
+	if err != nil {
+		return "", err
+	}
+
+	if kind == "text" {
+		return element.Text()
+	}
+
+	for _, attribute := range attributes {
+		value, err := element.Attribute(attribute)
+		if err != nil {
+			return "", err
+		}
+
+		if value == nil {
+			continue
+		}
+
+		if value := field.attributeValue(attribute, *value); value != "" {
+			return value, nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/go-rod/rod"
+)
+
+// scrollSettleTime is how long the DOM must stay unchanged after scrolling
+// before new items are read.
+const scrollSettleTime = time.Second
+
+// ProfileScrapper scrapes any marketplace described by a site profile.
+type ProfileScrapper struct {
+	profiles *ProfileStore
+	name     string
+	options  scrapperOptions
+}
+
+func NewProfileScrapper(profiles *ProfileStore, name string, opts ...Option) *ProfileScrapper {
+	return &ProfileScrapper{
+		profiles: profiles,
+		name:     name,
+		options:  newScrapperOptions(opts),
+	}
+}
+
+func (s *ProfileScrapper) FindByFilter(ctx context.Context, filter dtos.AutoFilter) (autos []*dtos.AutoFilterResponse, err error) {
+	profile, err := s.profiles.Profile(s.name)
+	if err != nil {
+		return nil, err
+	}
+
+	run := s.options.startRun(profile.Name, BackendRod, filter)
+	e := newExtraction()
+
+	defer func() {
+		err = s.options.finishRun(run, e, len(autos), err)
+	}()
+
+	searchURL, err := profile.BuildURL(filter, s.options.baseURL, s.options.catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	run.URL = searchURL
+
+	release, err := s.options.acquirePage(ctx, searchURL, profile.Politeness)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	proxy, err := s.options.nextProxy()
+	if err != nil {
+		return nil, err
+	}
+
+	run.useProxy(proxy)
+
+	fingerprint := s.options.nextFingerprint()
+	run.Fingerprint = fingerprint.Name
+
+	browser, err := launchBrowser(ctx, proxy, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	defer browser.MustClose()
+
+	defer func() {
+		s.options.reportProxy(proxy, err)
+	}()
+
+	page, err := newPage(browser, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	defer page.MustClose()
+
+	console, stopConsole := s.options.captureConsole(page)
+	defer stopConsole()
+
+	// runs while the page is still open
+	defer func() {
+		err = s.options.saveArtifacts(ctx, page, console, run, len(autos), err)
+	}()
+
+	stopIntercepting := s.options.interceptRequests(page, profile, searchURL)
+	defer stopIntercepting()
+
+	capture, stopCapture := captureData(page, profile.Data)
+	defer stopCapture()
+
+	stopTracking := run.trackBytes(page)
+	defer stopTracking()
+
+	// the search stops with ctx, the page is closed regardless
+	searchPage := page.Context(ctx)
+
+	autos = make([]*dtos.AutoFilterResponse, 0)
+	seen := make(map[string]bool)
+	pageURL := searchURL
+
+	for index := 0; index < profile.Pagination.Pages(); index++ {
+		if index > 0 {
+			if err := s.options.navigateTo(ctx, pageURL, profile.Politeness); err != nil {
+				break
+			}
+		}
+
+		run.Pages++
+
+		if index == 0 || profile.Pagination.Strategy != PaginationScroll {
+			if err := searchPage.Navigate(pageURL); err != nil {
+				return nil, err
+			}
+		}
+
+		waitErr := s.waitForResults(searchPage, profile)
+
+		// listings shipped as JSON survive redesigns better than the markup
+		found := capture.listings(page, pageURL, e)
+
+		if len(found) == 0 {
+			if waitErr != nil {
+				// an empty first page is an error, a missing later page ends the search
+				if index == 0 {
+					// the scrape counts with no items seen, so a lost container shows up
+					s.options.recordQuality(profile, e)
+
+					return nil, waitErr
+				}
+
+				break
+			}
+
+			found, err = s.extractItems(ctx, searchPage, profile, pageURL, seen, e)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		s.options.recordPage(page, pageURL)
+
+		added := 0
+
+		for _, auto := range found {
+			if seen[auto.URL] {
+				continue
+			}
+
+			seen[auto.URL] = true
+			autos = append(autos, auto)
+			added++
+		}
+
+		if added == 0 && index > 0 {
+			break
+		}
+
+		nextURL, ok, err := s.nextPage(ctx, searchPage, profile, searchURL, pageURL, index)
+		if err != nil || !ok {
+			break
+		}
+
+		pageURL = nextURL
+	}
+
+	// a canceled search ends between pages, without its later results
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.options.recordQuality(profile, e)
+
+	return autos, nil
+}
+
+// waitForResults blocks until the page satisfies the profile wait conditions.
+func (s *ProfileScrapper) waitForResults(page *rod.Page, profile *SiteProfile) error {
+	timeout := profile.Wait.WaitTimeout()
+
+	selector := profile.Wait.Selector
+	if selector == "" {
+		selector = profile.ListContainer
+	}
+
+	if _, err := page.Timeout(timeout).Element(selector); err != nil {
+		return missingResults(page, profile, err)
+	}
+
+	if profile.Wait.Idle {
+		if err := page.WaitIdle(timeout); err != nil {
+			return err
+		}
+	}
+
+	if profile.Wait.Stable > 0 {
+		if err := page.Timeout(timeout).WaitStable(profile.Wait.Stable); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractItems maps every item in the list container to a response. Items
+// without URL, title or price are skipped; the image is best effort since
+// many sites lazy load it. Items whose URL is already seen, like those still
+// on the page after scrolling, are neither extracted nor counted again.
+func (s *ProfileScrapper) extractItems(ctx context.Context, page *rod.Page, profile *SiteProfile, pageURL string, seen map[string]bool, e *extraction) ([]*dtos.AutoFilterResponse, error) {
+	container, err := page.Element(profile.ListContainer)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := container.Elements(profile.Item)
+	if err != nil {
+		return nil, err
+	}
+
+	autos := make([]*dtos.AutoFilterResponse, 0, len(items))
+
+	for _, item := range items {
+		url, err := s.field(item, profile, FieldURL, pageURL)
+		if err == nil && seen[url] {
+			continue
+		}
+
+		e.item()
+
+		if !e.require(FieldURL, err) {
+			continue
+		}
+
+		title, err := s.field(item, profile, FieldTitle, pageURL)
+		if !e.require(FieldTitle, err) {
+			continue
+		}
+
+		textPrice, err := s.field(item, profile, FieldPrice, pageURL)
+
+		var price float64
+		if err == nil {
+			price, err = strconv.ParseFloat(textPrice, 64)
+		}
+
+		if !e.require(FieldPrice, err) {
+			continue
+		}
+
+		imageURL, err := s.image(ctx, item, profile, pageURL)
+		e.optional(FieldImage, err)
+
+		// a failed lookup only loses the extra images
+		values, _ := findFieldValues(item, profile.Fields[FieldImages])
+		images := listingImages(imageURL, values, profile.Fields[FieldImages], pageURL)
+
+		if imageURL == "" && len(images) > 0 {
+			imageURL = images[0]
+		}
+
+		autos = append(autos, &dtos.AutoFilterResponse{
+			Title:     title,
+			Price:     price,
+			URL:       url,
+			ImageURL:  imageURL,
+			ImageURLs: images,
+		})
+		e.done()
+	}
+
+	return autos, nil
+}
+
+func (s *ProfileScrapper) field(item *rod.Element, profile *SiteProfile, name string, pageURL string) (string, error) {
+	field := profile.Fields[name]
+
+	_, value, err := findField(item, name, field)
+	if err != nil {
+		return "", err
+	}
+
+	return field.Apply(value, pageURL)
+}
+
+// image returns the main image of item, scrolling it into view when the
+// image is only set once visible.
+func (s *ProfileScrapper) image(ctx context.Context, item *rod.Element, profile *SiteProfile, pageURL string) (string, error) {
+	field := profile.Fields[FieldImage]
+
+	_, value, err := findLazyField(item, FieldImage, field, func() {
+		s.options.pause(ctx, profile.Politeness)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return field.Apply(value, pageURL)
+}
+
+// nextPage returns the URL of the page following index, or false when the
+// pagination strategy has no more pages.
+func (s *ProfileScrapper) nextPage(ctx context.Context, page *rod.Page, profile *SiteProfile, searchURL string, pageURL string, index int) (string, bool, error) {
+	if index+1 >= profile.Pagination.Pages() {
+		return "", false, nil
+	}
+
+	switch profile.Pagination.Strategy {
+	case PaginationQuery:
+		nextURL, err := profile.Pagination.PageURL(searchURL, index+1)
+		if err != nil {
+			return "", false, err
+		}
+
+		return nextURL, true, nil
+	case PaginationNextLink:
+		found, next, err := page.Has(profile.Pagination.NextSelector)
+		if err != nil || !found {
+			return "", false, err
+		}
+
+		href, err := next.Attribute("href")
+		if err != nil {
+			return "", false, err
+		}
+
+		if href == nil || *href == "" {
+			return "", false, errors.New("next page link has no href")
+		}
+
+		nextURL, err := profile.AbsoluteURL(*href, pageURL)
+		if err != nil {
+			return "", false, err
+		}
+
+		return nextURL, true, nil
+	case PaginationScroll:
+		s.options.pause(ctx, profile.Politeness)
+
+		if _, err := page.Eval(`() => window.scrollTo(0, document.body.scrollHeight)`); err != nil {
+			return "", false, err
+		}
+
+		if err := page.Timeout(profile.Wait.WaitTimeout()).WaitStable(scrollSettleTime); err != nil {
+			return "", false, err
+		}
+
+		return pageURL, true, nil
+	}
+
+	return "", false, nil
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed profiles
+var defaultProfiles embed.FS
+
+// ProfileStore holds the site profiles known to the service. The embedded
+// defaults are always loaded; profiles found in dir override them by name.
+type ProfileStore struct {
+	dir string
+
+	mu       sync.RWMutex
+	profiles map[string]*SiteProfile
+	stamp    string
+}
+
+// NewProfileStore loads and validates every profile. dir may be empty to use
+// only the embedded defaults.
+func NewProfileStore(dir string) (*ProfileStore, error) {
+	s := &ProfileStore{dir: dir}
+
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Profile returns the current profile registered under name.
+func (s *ProfileStore) Profile(name string) (*SiteProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profile, ok := s.profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("site profile %q not found", name)
+	}
+
+	return profile, nil
+}
+
+// Names returns the names of all loaded profiles.
+func (s *ProfileStore) Names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.profiles))
+	for name := range s.profiles {
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// Reload reads all profiles again. The current profiles are kept when any of
+// the new ones is invalid.
+func (s *ProfileStore) Reload() error {
+	profiles, err := loadProfiles(defaultProfiles, "profiles")
+	if err != nil {
+		return err
+	}
+
+	stamp := ""
+
+	if s.dir != "" {
+		stamp, err = dirStamp(s.dir)
+		if err != nil {
+			return err
+		}
+
+		overrides, err := loadProfiles(os.DirFS(s.dir), ".")
+		if err != nil {
+			return err
+		}
+
+		for name, profile := range overrides {
+			profiles[name] = profile
+		}
+	}
+
+	s.mu.Lock()
+	s.profiles = profiles
+	s.stamp = stamp
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Watch polls the profile directory every interval and reloads it when a file
+// changes, until ctx is done.
+func (s *ProfileStore) Watch(ctx context.Context, interval time.Duration) {
+	if s.dir == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stamp, err := dirStamp(s.dir)
+			if err != nil {
+				log.Printf("site profiles: %v", err)
+				continue
+			}
+
+			s.mu.RLock()
+			changed := stamp != s.stamp
+			s.mu.RUnlock()
+
+			if !changed {
+				continue
+			}
+
+			if err := s.Reload(); err != nil {
+				log.Printf("site profiles: keeping previous profiles: %v", err)
+				continue
+			}
+
+			log.Println("site profiles reloaded from", s.dir)
+		}
+	}
+}
+
+func loadProfiles(fsys fs.FS, dir string) (map[string]*SiteProfile, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]*SiteProfile)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isProfileFile(entry.Name()) {
+			continue
+		}
+
+		path := filepath.ToSlash(filepath.Join(dir, entry.Name()))
+
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, err
+		}
+
+		profile, err := ParseSiteProfile(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+
+		if _, ok := profiles[profile.Name]; ok {
+			return nil, fmt.Errorf("%s: duplicate profile %q", entry.Name(), profile.Name)
+		}
+
+		profiles[profile.Name] = profile
+	}
+
+	return profiles, nil
+}
+
+// dirStamp summarizes the names, sizes and modification times of the profile
+// files in dir so changes can be detected without reading them.
+func dirStamp(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var stamp strings.Builder
+
+	for _, entry := range entries {
+		if entry.IsDir() || !isProfileFile(entry.Name()) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&stamp, "%s:%d:%d;", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+
+	return stamp.String(), nil
+}
+
+func isProfileFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+
+	return false
+}
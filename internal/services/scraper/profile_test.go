@@ -0,0 +1,64 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldSpecValues(t *testing.T) {
+	const placeholder = "https://cds.neoauto.pe/neoauto3/img/loader_black.gif"
+
+	tests := []struct {
+		name  string
+		field FieldSpec
+		value string
+		want  []string
+	}{
+		{
+			name:  "plain value",
+			field: FieldSpec{Placeholder: placeholder},
+			value: " https://cde.neoauto.pe/1.jpg ",
+			want:  []string{"https://cde.neoauto.pe/1.jpg"},
+		},
+		{
+			name:  "placeholder",
+			field: FieldSpec{Placeholder: placeholder},
+			value: placeholder,
+			want:  []string{},
+		},
+		{
+			name:  "json array",
+			field: FieldSpec{JSON: ".", Placeholder: placeholder},
+			value: `["https://cde.neoauto.pe/1.jpg", "` + placeholder + `", "https://cde.neoauto.pe/2.jpg"]`,
+			want:  []string{"https://cde.neoauto.pe/1.jpg", "https://cde.neoauto.pe/2.jpg"},
+		},
+		{
+			name:  "json-ld path",
+			field: FieldSpec{JSON: "image.contentUrl"},
+			value: `{"@type": "Car", "image": [{"contentUrl": "https://cde.neoauto.pe/1.jpg"}, {"contentUrl": "https://cde.neoauto.pe/2.jpg"}]}`,
+			want:  []string{"https://cde.neoauto.pe/1.jpg", "https://cde.neoauto.pe/2.jpg"},
+		},
+		{
+			name:  "invalid json",
+			field: FieldSpec{JSON: "image"},
+			value: `{"image": `,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.field.values(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWidestSrcset(t *testing.T) {
+	srcset := "https://cde.neoauto.pe/360x240/1.jpg 360w, https://cde.neoauto.pe/720x480/1.jpg 720w, https://cde.neoauto.pe/180x120/1.jpg 180w"
+
+	if got, want := widestSrcset(srcset), "https://cde.neoauto.pe/720x480/1.jpg"; got != want {
+		t.Errorf("widestSrcset() = %q, want %q", got, want)
+	}
+}
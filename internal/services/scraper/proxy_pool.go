@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Proxy rotation modes. With per request rotation every HTTP request may use
+// a different proxy; with per session rotation a search keeps the same proxy
+// for all its requests. Browsers always keep one proxy per session.
+const (
+	ProxyRotationRequest = "request"
+	ProxyRotationSession = "session"
+)
+
+// Outcomes reported for a request made through a proxy.
+type ProxyOutcome int
+
+const (
+	ProxySucceeded ProxyOutcome = iota
+	ProxyFailed
+	ProxyBlocked
+)
+
+const (
+	defaultProxyBench = 5 * time.Minute
+	maxProxyBench     = time.Hour
+
+	// proxyBenchThreshold is the failure count that benches a proxy. Block
+	// pages count as several failures since they rarely clear by retrying.
+	proxyBenchThreshold = 3
+	proxyBlockedWeight  = 3
+
+	// proxyScoreWeight is the weight of the last outcome in the health score.
+	proxyScoreWeight = 0.2
+)
+
+// ErrNoProxyAvailable is returned when every proxy of the pool is benched.
+var ErrNoProxyAvailable = errors.New("no proxy available")
+
+// Proxy is an outbound proxy handed out by a ProxyPool.
+type Proxy struct {
+	URL       *url.URL
+	Transport *http.Transport
+}
+
+// ProxyStats is the health of a proxy, with credentials removed from its URL.
+type ProxyStats struct {
+	URL          string
+	Score        float64
+	Failures     int
+	BenchedUntil time.Time
+}
+
+// ProxyPool rotates outbound HTTP and SOCKS5 proxies, scoring them on failures
+// and block pages and benching the ones that go bad for a while.
+type ProxyPool struct {
+	rotation string
+	bench    time.Duration
+
+	mu      sync.Mutex
+	proxies []*proxyState
+}
+
+type proxyState struct {
+	proxy        *Proxy
+	score        float64
+	current      float64
+	failures     int
+	benches      int
+	benchedUntil time.Time
+}
+
+// NewProxyPool validates the proxy URLs. bench is how long a bad proxy is
+// set aside the first time; it doubles every time the proxy is benched again.
+func NewProxyPool(rawURLs []string, rotation string, bench time.Duration) (*ProxyPool, error) {
+	switch rotation {
+	case "":
+		rotation = ProxyRotationSession
+	case ProxyRotationRequest, ProxyRotationSession:
+	default:
+		return nil, fmt.Errorf("unknown proxy rotation %q", rotation)
+	}
+
+	if bench <= 0 {
+		bench = defaultProxyBench
+	}
+
+	pool := &ProxyPool{
+		rotation: rotation,
+		bench:    bench,
+	}
+
+	for _, rawURL := range rawURLs {
+		proxyURL, err := url.Parse(strings.TrimSpace(rawURL))
+		if err != nil {
+			return nil, fmt.Errorf("proxy %q: %w", rawURL, err)
+		}
+
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("proxy %q: unsupported scheme %q", proxyURL.Redacted(), proxyURL.Scheme)
+		}
+
+		if proxyURL.Host == "" {
+			return nil, fmt.Errorf("proxy %q: missing host", proxyURL.Redacted())
+		}
+
+		pool.proxies = append(pool.proxies, &proxyState{
+			proxy: &Proxy{
+				URL:       proxyURL,
+				Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)},
+			},
+			score: 1,
+		})
+	}
+
+	if len(pool.proxies) == 0 {
+		return nil, errors.New("proxy pool is empty")
+	}
+
+	return pool, nil
+}
+
+// ParseProxyList splits a comma or newline separated list of proxy URLs.
+func ParseProxyList(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ' '
+	})
+}
+
+// Rotation returns the rotation mode of the pool.
+func (p *ProxyPool) Rotation() string {
+	return p.rotation
+}
+
+// Size returns the number of proxies in the pool.
+func (p *ProxyPool) Size() int {
+	return len(p.proxies)
+}
+
+// Next returns the next proxy that is not benched, by smooth weighted round
+// robin on the health scores: proxies healthy alike take turns, and one that
+// starts failing gets fewer requests well before it is benched.
+func (p *ProxyPool) Next() (*Proxy, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+
+	var next *proxyState
+	total := 0.0
+
+	for _, state := range p.proxies {
+		if now.Before(state.benchedUntil) {
+			continue
+		}
+
+		state.current += state.score
+		total += state.score
+
+		if next == nil || state.current > next.current {
+			next = state
+		}
+	}
+
+	if next == nil {
+		return nil, ErrNoProxyAvailable
+	}
+
+	next.current -= total
+
+	return next.proxy, nil
+}
+
+// Report records the outcome of a request made through proxy.
+func (p *ProxyPool) Report(proxy *Proxy, outcome ProxyOutcome) {
+	if proxy == nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, state := range p.proxies {
+		if state.proxy != proxy {
+			continue
+		}
+
+		switch outcome {
+		case ProxySucceeded:
+			state.score += proxyScoreWeight * (1 - state.score)
+			state.failures = 0
+			state.benches = 0
+			return
+		case ProxyFailed:
+			state.failures++
+		case ProxyBlocked:
+			state.failures += proxyBlockedWeight
+		}
+
+		state.score -= proxyScoreWeight * state.score
+
+		if state.failures >= proxyBenchThreshold {
+			bench := p.bench << state.benches
+			if bench > maxProxyBench || bench <= 0 {
+				bench = maxProxyBench
+			}
+
+			state.benchedUntil = time.Now().Add(bench)
+			state.failures = 0
+			state.benches++
+		}
+
+		return
+	}
+}
+
+// Stats returns the health of every proxy.
+func (p *ProxyPool) Stats() []ProxyStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]ProxyStats, 0, len(p.proxies))
+	for _, state := range p.proxies {
+		stats = append(stats, ProxyStats{
+			URL:          state.proxy.URL.Redacted(),
+			Score:        state.score,
+			Failures:     state.failures,
+			BenchedUntil: state.benchedUntil,
+		})
+	}
+
+	return stats
+}
+
+// WriteMetrics writes the health of every proxy in the Prometheus text
+// format.
+func (p *ProxyPool) WriteMetrics(w io.Writer) error {
+	stats := p.Stats()
+	now := time.Now()
+
+	metrics := []struct {
+		name, help string
+		value      func(ProxyStats) float64
+	}{
+		{"autoradar_proxy_score", "Health score of the proxy, from 0 to 1.", func(s ProxyStats) float64 {
+			return s.Score
+		}},
+		{"autoradar_proxy_failures", "Failures of the proxy since it last succeeded or was benched.", func(s ProxyStats) float64 {
+			return float64(s.Failures)
+		}},
+		{"autoradar_proxy_benched", "Whether the proxy is benched.", func(s ProxyStats) float64 {
+			if now.Before(s.BenchedUntil) {
+				return 1
+			}
+			return 0
+		}},
+	}
+
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", metric.name, metric.help, metric.name); err != nil {
+			return err
+		}
+
+		for _, proxy := range stats {
+			if _, err := fmt.Fprintf(w, "%s{proxy=%q} %g\n", metric.name, proxy.URL, metric.value(proxy)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Outcomes of a listing field.
+const (
+	FieldFound   = "found"
+	FieldMissing = "missing"
+	FieldInvalid = "invalid"
+)
+
+// skipDuplicate is the skip reason of items already extracted.
+const skipDuplicate = "duplicate"
+
+const (
+	defaultMinExtractionRatio = 0.8
+	defaultMinQualityItems    = 5
+	defaultMaxEmptyScrapes    = 3
+)
+
+// ErrFieldNotFound is returned when no selector of a field yields a value.
+var ErrFieldNotFound = errors.New("field not found")
+
+// QualityPolicy is when a source alerts about selector drift: a scrape that
+// saw at least MinItems items and extracted less than MinExtractionRatio of
+// them, or MaxEmptyScrapes scrapes in a row that saw no item at all from a
+// source that returned items before, as when its list container is gone.
+// Zero values use the defaults.
+type QualityPolicy struct {
+	MinExtractionRatio float64 `yaml:"min_extraction_ratio"`
+	MinItems           int     `yaml:"min_items"`
+	MaxEmptyScrapes    int     `yaml:"max_empty_scrapes"`
+}
+
+func (p QualityPolicy) minExtractionRatio() float64 {
+	if p.MinExtractionRatio <= 0 {
+		return defaultMinExtractionRatio
+	}
+
+	return p.MinExtractionRatio
+}
+
+func (p QualityPolicy) minItems() int {
+	if p.MinItems <= 0 {
+		return defaultMinQualityItems
+	}
+
+	return p.MinItems
+}
+
+func (p QualityPolicy) maxEmptyScrapes() int {
+	if p.MaxEmptyScrapes <= 0 {
+		return defaultMaxEmptyScrapes
+	}
+
+	return p.MaxEmptyScrapes
+}
+
+// extraction counts the items of one scrape and the outcome of their fields.
+type extraction struct {
+	seen      int
+	extracted int
+	skipped   map[string]int
+	fields    map[string]map[string]int
+}
+
+func newExtraction() *extraction {
+	return &extraction{
+		skipped: make(map[string]int),
+		fields:  make(map[string]map[string]int),
+	}
+}
+
+func (e *extraction) item() {
+	e.seen++
+}
+
+func (e *extraction) done() {
+	e.extracted++
+}
+
+// skip records an item skipped for reason.
+func (e *extraction) skip(reason string) {
+	e.skipped[reason]++
+}
+
+// require records the outcome of a required field and reports whether the
+// item can go on; otherwise it is skipped for the field outcome.
+func (e *extraction) require(field string, err error) bool {
+	outcome := e.optional(field, err)
+	if outcome != FieldFound {
+		e.skip(field + "_" + outcome)
+	}
+
+	return outcome == FieldFound
+}
+
+// optional records the outcome of a best effort field.
+func (e *extraction) optional(field string, err error) string {
+	outcome := fieldOutcome(err)
+
+	if e.fields[field] == nil {
+		e.fields[field] = make(map[string]int)
+	}
+
+	e.fields[field][outcome]++
+
+	return outcome
+}
+
+// fieldOutcome is the outcome of a field read with err.
+func fieldOutcome(err error) string {
+	switch {
+	case errors.Is(err, ErrFieldNotFound):
+		return FieldMissing
+	case err != nil:
+		return FieldInvalid
+	}
+
+	return FieldFound
+}
+
+// SourceQuality is the extraction quality of a source since startup.
+type SourceQuality struct {
+	Source    string
+	Scrapes   int
+	Seen      int
+	Extracted int
+	// Skipped counts skipped items by reason, e.g. "price_missing".
+	Skipped map[string]int
+	// Fields counts field outcomes by field and outcome.
+	Fields map[string]map[string]int
+
+	LastRatio float64
+	// EmptyStreak counts the last scrapes in a row that saw no item.
+	EmptyStreak int
+	Alerting    bool
+	Alerts      int
+	LastAlert   time.Time
+}
+
+// Ratio is the share of the items seen that were extracted.
+func (q SourceQuality) Ratio() float64 {
+	if q.Seen == 0 {
+		return 0
+	}
+
+	return float64(q.Extracted) / float64(q.Seen)
+}
+
+// Quality aggregates the extraction counts of every source and alerts when
+// a scrape extracts too few of the items it sees, which usually means the
+// site markup drifted away from its profile.
+type Quality struct {
+	mu      sync.Mutex
+	sources map[string]*SourceQuality
+}
+
+func NewQuality() *Quality {
+	return &Quality{
+		sources: make(map[string]*SourceQuality),
+	}
+}
+
+// WithQuality records the extraction counts of the scrapes in quality, which
+// should be shared by all the scrappers.
+func WithQuality(quality *Quality) Option {
+	return func(o *scrapperOptions) {
+		o.quality = quality
+	}
+}
+
+func (q *Quality) record(source string, policy QualityPolicy, e *extraction) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats, ok := q.sources[source]
+	if !ok {
+		stats = &SourceQuality{
+			Source:  source,
+			Skipped: make(map[string]int),
+			Fields:  make(map[string]map[string]int),
+		}
+		q.sources[source] = stats
+	}
+
+	// a source that never returned items may just have nothing to find
+	productive := stats.Seen > 0
+
+	stats.Scrapes++
+	stats.Seen += e.seen
+	stats.Extracted += e.extracted
+
+	for reason, count := range e.skipped {
+		stats.Skipped[reason] += count
+	}
+
+	for field, outcomes := range e.fields {
+		if stats.Fields[field] == nil {
+			stats.Fields[field] = make(map[string]int)
+		}
+
+		for outcome, count := range outcomes {
+			stats.Fields[field][outcome] += count
+		}
+	}
+
+	if e.seen == 0 {
+		stats.EmptyStreak++
+
+		// a selector that stopped matching sees nothing, scrape after scrape
+		if productive && stats.EmptyStreak >= policy.maxEmptyScrapes() {
+			stats.LastRatio = 0
+
+			if !stats.Alerting {
+				stats.Alerting = true
+				stats.Alerts++
+				stats.LastAlert = time.Now()
+
+				log.Printf("ALERT: %s saw no items in %d scrapes in a row", source, stats.EmptyStreak)
+			}
+		}
+
+		return
+	}
+
+	// items are back, whatever the ratio of a small scrape says
+	if stats.EmptyStreak >= policy.maxEmptyScrapes() {
+		stats.Alerting = false
+	}
+
+	stats.EmptyStreak = 0
+
+	// too few items say nothing about the markup
+	if e.seen < policy.minItems() {
+		return
+	}
+
+	stats.LastRatio = float64(e.extracted) / float64(e.seen)
+	stats.Alerting = stats.LastRatio < policy.minExtractionRatio()
+
+	if stats.Alerting {
+		stats.Alerts++
+		stats.LastAlert = time.Now()
+
+		log.Printf("ALERT: %s extracted %d of %d items (%.0f%%, below %.0f%%), skipped: %v",
+			source, e.extracted, e.seen, stats.LastRatio*100, policy.minExtractionRatio()*100, e.skipped)
+	}
+}
+
+// Sources returns the quality of every source that was scraped, by name.
+func (q *Quality) Sources() []SourceQuality {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	sources := make([]SourceQuality, 0, len(q.sources))
+
+	for _, name := range slices.Sorted(maps.Keys(q.sources)) {
+		stats := *q.sources[name]
+		stats.Skipped = maps.Clone(stats.Skipped)
+		stats.Fields = make(map[string]map[string]int, len(q.sources[name].Fields))
+
+		for field, outcomes := range q.sources[name].Fields {
+			stats.Fields[field] = maps.Clone(outcomes)
+		}
+
+		sources = append(sources, stats)
+	}
+
+	return sources
+}
+
+// WriteMetrics writes the quality counters in the Prometheus text format.
+func (q *Quality) WriteMetrics(w io.Writer) error {
+	sources := q.Sources()
+
+	metrics := []struct {
+		name, kind, help string
+		samples          func(SourceQuality) []string
+	}{
+		{"autoradar_scrape_runs_total", "counter", "Scrapes per source.", func(s SourceQuality) []string {
+			return []string{fmt.Sprintf("{source=%q} %d", s.Source, s.Scrapes)}
+		}},
+		{"autoradar_scrape_items_seen_total", "counter", "Items seen in the result pages.", func(s SourceQuality) []string {
+			return []string{fmt.Sprintf("{source=%q} %d", s.Source, s.Seen)}
+		}},
+		{"autoradar_scrape_items_extracted_total", "counter", "Items extracted as listings.", func(s SourceQuality) []string {
+			return []string{fmt.Sprintf("{source=%q} %d", s.Source, s.Extracted)}
+		}},
+		{"autoradar_scrape_items_skipped_total", "counter", "Items skipped, by reason.", func(s SourceQuality) []string {
+			var samples []string
+			for _, reason := range slices.Sorted(maps.Keys(s.Skipped)) {
+				samples = append(samples, fmt.Sprintf("{source=%q,reason=%q} %d", s.Source, reason, s.Skipped[reason]))
+			}
+			return samples
+		}},
+		{"autoradar_scrape_fields_total", "counter", "Field outcomes, by field.", func(s SourceQuality) []string {
+			var samples []string
+			for _, field := range slices.Sorted(maps.Keys(s.Fields)) {
+				for _, outcome := range slices.Sorted(maps.Keys(s.Fields[field])) {
+					samples = append(samples, fmt.Sprintf("{source=%q,field=%q,outcome=%q} %d", s.Source, field, outcome, s.Fields[field][outcome]))
+				}
+			}
+			return samples
+		}},
+		{"autoradar_scrape_extraction_ratio", "gauge", "Share of the items extracted by the last scrape.", func(s SourceQuality) []string {
+			return []string{fmt.Sprintf("{source=%q} %g", s.Source, s.LastRatio)}
+		}},
+		{"autoradar_scrape_empty_streak", "gauge", "Last scrapes in a row that saw no item.", func(s SourceQuality) []string {
+			return []string{fmt.Sprintf("{source=%q} %d", s.Source, s.EmptyStreak)}
+		}},
+		{"autoradar_scrape_quality_alerting", "gauge", "Whether the last scrape was below the extraction threshold.", func(s SourceQuality) []string {
+			alerting := 0
+			if s.Alerting {
+				alerting = 1
+			}
+			return []string{fmt.Sprintf("{source=%q} %d", s.Source, alerting)}
+		}},
+	}
+
+	for _, metric := range metrics {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind); err != nil {
+			return err
+		}
+
+		for _, source := range sources {
+			for _, sample := range metric.samples(source) {
+				if _, err := fmt.Fprintf(w, "%s%s\n", metric.name, sample); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordQuality adds the counts of a scrape of profile to the quality stats.
+func (o scrapperOptions) recordQuality(profile *SiteProfile, e *extraction) {
+	if o.quality == nil {
+		return
+	}
+
+	o.quality.record(profile.Name, profile.Quality, e)
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/enums"
+)
+
+func TestHTTPScrapperRecordsQuality(t *testing.T) {
+	profiles := newTestProfileStore(t)
+	srv := newFixtureServer(t)
+	quality := NewQuality()
+
+	s := NewHTTPScrapper(profiles, enums.NeoAuto.String(), WithBaseURL(srv.URL+"/"), WithQuality(quality))
+
+	autos, err := s.FindByFilter(context.Background(), dtos.AutoFilter{})
+	if err != nil {
+		t.Fatalf("FindByFilter() error = %v", err)
+	}
+
+	sources := quality.Sources()
+	if len(sources) != 1 {
+		t.Fatalf("Sources() = %d sources, want 1", len(sources))
+	}
+
+	got := sources[0]
+	if got.Source != enums.NeoAuto.String() || got.Scrapes != 1 {
+		t.Errorf("source = %q with %d scrapes, want %q with 1", got.Source, got.Scrapes, enums.NeoAuto.String())
+	}
+
+	// the fixture lists one car without price
+	if got.Seen != len(autos)+1 || got.Extracted != len(autos) {
+		t.Errorf("extracted %d of %d items, want %d of %d", got.Extracted, got.Seen, len(autos), len(autos)+1)
+	}
+
+	if got.Skipped["price_missing"] != 1 {
+		t.Errorf("Skipped = %v, want price_missing: 1", got.Skipped)
+	}
+
+	// below the default minimum of items, so no alert
+	if got.Alerting {
+		t.Error("Alerting = true, want false")
+	}
+}
+
+func TestQualityAlertsBelowExtractionRatio(t *testing.T) {
+	quality := NewQuality()
+	policy := QualityPolicy{MinExtractionRatio: 0.5, MinItems: 4}
+
+	drifted := newExtraction()
+	for i := range 4 {
+		drifted.item()
+		if drifted.require(FieldPrice, nil) && i == 0 {
+			drifted.done()
+		}
+	}
+	drifted.require(FieldTitle, ErrFieldNotFound)
+
+	quality.record("Test", policy, drifted)
+
+	got := quality.Sources()[0]
+	if !got.Alerting || got.Alerts != 1 {
+		t.Errorf("Alerting = %v with %d alerts, want true with 1", got.Alerting, got.Alerts)
+	}
+
+	if got.LastRatio != 0.25 {
+		t.Errorf("LastRatio = %g, want 0.25", got.LastRatio)
+	}
+
+	healthy := newExtraction()
+	for range 4 {
+		healthy.item()
+		healthy.done()
+	}
+
+	quality.record("Test", policy, healthy)
+
+	if got := quality.Sources()[0]; got.Alerting || got.Alerts != 1 {
+		t.Errorf("Alerting = %v with %d alerts, want false with 1", got.Alerting, got.Alerts)
+	}
+}
+
+func TestQualityAlertsWhenTheContainerIsGone(t *testing.T) {
+	profiles := newTestProfileStore(t)
+	fixtures := newFixtureServer(t)
+
+	var drifted atomic.Bool
+
+	// after a redesign the list container no longer matches
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := http.Get(fixtures.URL + r.URL.RequestURI())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if drifted.Load() {
+			body = bytes.ReplaceAll(body, []byte("s-results"), []byte("s-listings"))
+		}
+
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+
+	quality := NewQuality()
+	s := NewHTTPScrapper(profiles, enums.NeoAuto.String(), WithBaseURL(srv.URL+"/"), WithQuality(quality))
+
+	if autos, err := s.FindByFilter(context.Background(), dtos.AutoFilter{}); err != nil || len(autos) == 0 {
+		t.Fatalf("FindByFilter() = %d autos, %v, want some autos", len(autos), err)
+	}
+
+	drifted.Store(true)
+
+	for i := range defaultMaxEmptyScrapes {
+		if got := quality.Sources()[0]; got.Alerting {
+			t.Fatalf("Alerting after %d empty scrapes, want an alert after %d", i, defaultMaxEmptyScrapes)
+		}
+
+		if autos, err := s.FindByFilter(context.Background(), dtos.AutoFilter{}); err != nil || len(autos) != 0 {
+			t.Fatalf("FindByFilter() of the redesigned page = %d autos, %v, want none", len(autos), err)
+		}
+	}
+
+	got := quality.Sources()[0]
+	if !got.Alerting || got.Alerts != 1 || got.EmptyStreak != defaultMaxEmptyScrapes {
+		t.Errorf("Alerting = %v with %d alerts after %d empty scrapes, want an alert", got.Alerting, got.Alerts, got.EmptyStreak)
+	}
+
+	drifted.Store(false)
+
+	if _, err := s.FindByFilter(context.Background(), dtos.AutoFilter{}); err != nil {
+		t.Fatalf("FindByFilter() error = %v", err)
+	}
+
+	if got := quality.Sources()[0]; got.EmptyStreak != 0 || got.Alerting {
+		t.Errorf("EmptyStreak = %d, Alerting = %v once items are back, want 0 and false", got.EmptyStreak, got.Alerting)
+	}
+}
+
+func TestQualityIgnoresSourcesThatNeverFoundItems(t *testing.T) {
+	quality := NewQuality()
+
+	for range 2 * defaultMaxEmptyScrapes {
+		quality.record("Test", QualityPolicy{}, newExtraction())
+	}
+
+	if got := quality.Sources()[0]; got.Alerting {
+		t.Error("Alerting = true for a source that never found items, want false")
+	}
+}
+
+func TestQualityWriteMetrics(t *testing.T) {
+	quality := NewQuality()
+
+	e := newExtraction()
+	e.item()
+	e.require(FieldPrice, fmt.Errorf("%w: price", ErrFieldNotFound))
+	quality.record("Test", QualityPolicy{}, e)
+
+	var b strings.Builder
+	if err := quality.WriteMetrics(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"# TYPE autoradar_scrape_runs_total counter\n",
+		`autoradar_scrape_items_seen_total{source="Test"} 1` + "\n",
+		`autoradar_scrape_items_skipped_total{source="Test",reason="price_missing"} 1` + "\n",
+		`autoradar_scrape_fields_total{source="Test",field="price",outcome="missing"} 1` + "\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics missing %q in:\n%s", want, b.String())
+		}
+	}
+}
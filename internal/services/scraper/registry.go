@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/enums"
+)
+
+// ErrPartialResults is wrapped by the error Registry.FindByFilter returns
+// along with the results of the sources that answered, when others failed.
+var ErrPartialResults = errors.New("some sources failed")
+
+// SourceError is the failure of a source in a search of all of them.
+type SourceError struct {
+	Source string
+	Err    error
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Source, e.Err)
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// SourceErrors returns the failures of every source in err, in source order.
+func SourceErrors(err error) []*SourceError {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var sourceErrs []*SourceError
+		for _, err := range joined.Unwrap() {
+			sourceErrs = append(sourceErrs, SourceErrors(err)...)
+		}
+
+		return sourceErrs
+	}
+
+	var sourceErr *SourceError
+	if errors.As(err, &sourceErr) {
+		return []*SourceError{sourceErr}
+	}
+
+	return nil
+}
+
+// Registry holds the AutoScrapper of every source by ScrapperType. It is an
+// AutoScrapper itself that searches all sources at once.
+type Registry struct {
+	scrappers map[enums.ScrapperType]AutoScrapper
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		scrappers: make(map[enums.ScrapperType]AutoScrapper),
+	}
+}
+
+// Register adds the scrapper of a source, replacing any previous one.
+func (r *Registry) Register(source enums.ScrapperType, scrapper AutoScrapper) {
+	r.scrappers[source] = scrapper
+}
+
+// Get returns the scrapper registered for source.
+func (r *Registry) Get(source enums.ScrapperType) (AutoScrapper, bool) {
+	scrapper, ok := r.scrappers[source]
+	return scrapper, ok
+}
+
+// Sources returns the registered sources in ScrapperType order.
+func (r *Registry) Sources() []enums.ScrapperType {
+	sources := make([]enums.ScrapperType, 0, len(r.scrappers))
+	for source := range r.scrappers {
+		sources = append(sources, source)
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i] < sources[j]
+	})
+
+	return sources
+}
+
+// FindByFilter searches every source concurrently and merges the results in
+// source order. Failing sources, panicking ones included, are skipped: when
+// others answered, their results come with an ErrPartialResults error
+// carrying the failures, otherwise only with the failures.
+func (r *Registry) FindByFilter(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+	sources := r.Sources()
+	if len(sources) == 0 {
+		return nil, errors.New("no sources registered")
+	}
+
+	results := make([][]*dtos.AutoFilterResponse, len(sources))
+	errs := make([]error, len(sources))
+
+	var wg sync.WaitGroup
+
+	for i, source := range sources {
+		wg.Add(1)
+
+		go func(i int, source enums.ScrapperType) {
+			defer wg.Done()
+
+			// a Must method panicking in a scrape fails its source, not the process
+			defer func() {
+				if p := recover(); p != nil {
+					log.Printf("%s panicked: %v\n%s", source, p, debug.Stack())
+					errs[i] = &SourceError{Source: source.String(), Err: fmt.Errorf("scrape panicked: %v", p)}
+				}
+			}()
+
+			autos, err := r.scrappers[source].FindByFilter(ctx, filter)
+			if err != nil {
+				errs[i] = &SourceError{Source: source.String(), Err: err}
+				return
+			}
+
+			results[i] = autos
+		}(i, source)
+	}
+
+	wg.Wait()
+
+	autos := make([]*dtos.AutoFilterResponse, 0)
+	failed := 0
+
+	for i := range sources {
+		if errs[i] != nil {
+			log.Println("Source failed:", errs[i])
+			failed++
+			continue
+		}
+
+		autos = append(autos, results[i]...)
+	}
+
+	switch failed {
+	case 0:
+		return autos, nil
+	case len(sources):
+		return nil, errors.Join(errs...)
+	}
+
+	return autos, fmt.Errorf("%w: %w", ErrPartialResults, errors.Join(errs...))
+}
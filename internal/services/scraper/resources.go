@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// blockableResourceTypes are the resource types a ResourcePolicy may block.
+// Documents are never blocked since they are the pages being scraped.
+var blockableResourceTypes = []proto.NetworkResourceType{
+	proto.NetworkResourceTypeStylesheet,
+	proto.NetworkResourceTypeImage,
+	proto.NetworkResourceTypeMedia,
+	proto.NetworkResourceTypeFont,
+	proto.NetworkResourceTypeScript,
+	proto.NetworkResourceTypeTextTrack,
+	proto.NetworkResourceTypeXHR,
+	proto.NetworkResourceTypeFetch,
+	proto.NetworkResourceTypeEventSource,
+	proto.NetworkResourceTypeWebSocket,
+	proto.NetworkResourceTypeManifest,
+	proto.NetworkResourceTypePing,
+	proto.NetworkResourceTypeOther,
+}
+
+// ResourcePolicy lists the requests a browser page does not need to render
+// the results: resource Types (e.g. image, font, media), Domains with their
+// subdomains and, with ThirdParty, every domain other than the site itself
+// and the Allow list.
+type ResourcePolicy struct {
+	Types      []string `yaml:"types"`
+	Domains    []string `yaml:"domains"`
+	ThirdParty bool     `yaml:"third_party"`
+	Allow      []string `yaml:"allow"`
+
+	types []proto.NetworkResourceType
+}
+
+func (r *ResourcePolicy) compile() error {
+	r.types = nil
+
+	for _, name := range r.Types {
+		i := slices.IndexFunc(blockableResourceTypes, func(t proto.NetworkResourceType) bool {
+			return strings.EqualFold(string(t), name)
+		})
+		if i < 0 {
+			return fmt.Errorf("unknown resource type %q", name)
+		}
+
+		r.types = append(r.types, blockableResourceTypes[i])
+	}
+
+	return nil
+}
+
+func (r ResourcePolicy) enabled() bool {
+	return len(r.types) > 0 || len(r.Domains) > 0 || r.ThirdParty
+}
+
+// blocks reports whether a request of resourceType to requestURL is blocked
+// on a page of the site at siteHost.
+func (r ResourcePolicy) blocks(resourceType proto.NetworkResourceType, requestURL *url.URL, siteHost string) bool {
+	if resourceType == proto.NetworkResourceTypeDocument {
+		return false
+	}
+
+	if slices.Contains(r.types, resourceType) {
+		return true
+	}
+
+	host := requestURL.Hostname()
+
+	for _, domain := range r.Domains {
+		if matchesDomain(host, domain) {
+			return true
+		}
+	}
+
+	if !r.ThirdParty || matchesDomain(host, siteHost) {
+		return false
+	}
+
+	for _, domain := range r.Allow {
+		if matchesDomain(host, domain) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesDomain reports whether host is domain or one of its subdomains. A
+// leading "www." of domain is ignored so site hosts cover their subdomains.
+func matchesDomain(host string, domain string) bool {
+	domain = strings.TrimPrefix(strings.ToLower(domain), "www.")
+	host = strings.ToLower(host)
+
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// interceptRequests prepares page before it navigates to pageURL: requests
+// blocked by the resource policy of profile fail, and in replay mode the rest
+// are served from the fixture directory. In record mode XHR and fetch
+// responses are saved as they arrive. The returned function stops it.
+func (o scrapperOptions) interceptRequests(page *rod.Page, profile *SiteProfile, pageURL string) func() {
+	stopRecording := func() {}
+	if o.fixtureMode == FixtureModeRecord {
+		stopRecording = o.recordResponses(page)
+	}
+
+	if o.fixtureMode != FixtureModeReplay && !profile.Resources.enabled() {
+		return stopRecording
+	}
+
+	var siteHost string
+	if u, err := url.Parse(pageURL); err == nil {
+		siteHost = u.Hostname()
+	}
+
+	router := page.HijackRequests()
+
+	router.MustAdd("*", func(ctx *rod.Hijack) {
+		if profile.Resources.blocks(ctx.Request.Type(), ctx.Request.URL(), siteHost) {
+			ctx.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+			return
+		}
+
+		if o.fixtureMode == FixtureModeReplay {
+			o.replayRequest(ctx)
+			return
+		}
+
+		ctx.ContinueRequest(&proto.FetchContinueRequest{})
+	})
+
+	go router.Run()
+
+	return func() {
+		router.MustStop()
+		stopRecording()
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestResourcePolicyBlocks(t *testing.T) {
+	policy := ResourcePolicy{
+		Types:      []string{"image", "Font"},
+		Domains:    []string{"doubleclick.net"},
+		ThirdParty: true,
+		Allow:      []string{"neoauto.pe"},
+	}
+	if err := policy.compile(); err != nil {
+		t.Fatalf("compile() error = %v", err)
+	}
+
+	tests := []struct {
+		resourceType proto.NetworkResourceType
+		url          string
+		want         bool
+	}{
+		{proto.NetworkResourceTypeDocument, "https://www.neoauto.com/venta-de-autos-usados", false},
+		{proto.NetworkResourceTypeImage, "https://cde.neoauto.pe/autos_usados/360x240/1741200_1.jpg", true},
+		{proto.NetworkResourceTypeFont, "https://www.neoauto.com/fonts/roboto.woff2", true},
+		{proto.NetworkResourceTypeScript, "https://www.neoauto.com/js/app.js", false},
+		{proto.NetworkResourceTypeScript, "https://static.neoauto.com/js/app.js", false},
+		{proto.NetworkResourceTypeScript, "https://cds.neoauto.pe/neoauto3/js/results.js", false},
+		{proto.NetworkResourceTypeScript, "https://securepubads.g.doubleclick.net/tag/js/gpt.js", true},
+		{proto.NetworkResourceTypeXHR, "https://www.google-analytics.com/g/collect", true},
+	}
+
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+
+		if got := policy.blocks(tt.resourceType, u, "www.neoauto.com"); got != tt.want {
+			t.Errorf("blocks(%s, %s) = %v, want %v", tt.resourceType, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestResourcePolicyRejectsUnknownType(t *testing.T) {
+	for _, name := range []string{"document", "pictures"} {
+		policy := ResourcePolicy{Types: []string{name}}
+		if err := policy.compile(); err == nil {
+			t.Errorf("compile() with type %q error = nil", name)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"github.com/redis/go-redis/v9"
+)
+
+// Error classes of a scrape run, from the error it failed with.
+const (
+	ErrorClassNone               = ""
+	ErrorClassBlocked            = "blocked"
+	ErrorClassLayoutChanged      = "layout_changed"
+	ErrorClassBrowserUnavailable = "browser_unavailable"
+	ErrorClassNoProxy            = "no_proxy"
+	ErrorClassTimeout            = "timeout"
+	ErrorClassFetch              = "fetch"
+	ErrorClassUnknown            = "unknown"
+)
+
+const (
+	runStream            = "scrape:runs"
+	defaultRunLogMaxLen  = 10000
+	defaultRunQueryLimit = 20
+	maxRunQueryLimit     = 200
+	runRecordTimeout     = 2 * time.Second
+)
+
+// ScrapeRun is the audit record of one scrape of a source by one backend.
+type ScrapeRun struct {
+	ID      string `json:"id"`
+	Source  string `json:"source"`
+	Backend string `json:"backend"`
+	// Filter is the normalized search filter, see NormalizeFilter.
+	Filter      string    `json:"filter"`
+	URL         string    `json:"url"`
+	StartedAt   time.Time `json:"started_at"`
+	EndedAt     time.Time `json:"ended_at"`
+	Pages       int       `json:"pages"`
+	Seen        int       `json:"seen"`
+	Found       int       `json:"found"`
+	Skipped     int       `json:"skipped"`
+	Bytes       int64     `json:"bytes"`
+	Proxy       string    `json:"proxy,omitempty"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	ErrorClass  string    `json:"error_class,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	// ArtifactsID is the scrape ID the page artifacts were saved under, when
+	// the scrape failed or found nothing.
+	ArtifactsID string `json:"artifacts_id,omitempty"`
+}
+
+// RunQuery selects the most recent runs, optionally of one source or error
+// class.
+type RunQuery struct {
+	Source     string
+	ErrorClass string
+	Limit      int
+}
+
+func (q RunQuery) limit() int {
+	if q.Limit <= 0 {
+		return defaultRunQueryLimit
+	}
+
+	return min(q.Limit, maxRunQueryLimit)
+}
+
+func (q RunQuery) matches(run ScrapeRun) bool {
+	return (q.Source == "" || strings.EqualFold(q.Source, run.Source)) &&
+		(q.ErrorClass == "" || q.ErrorClass == run.ErrorClass)
+}
+
+// RunLog keeps the audit records of the scrape runs.
+type RunLog interface {
+	Record(ctx context.Context, run ScrapeRun) error
+	// List returns the runs matching query, most recent first.
+	List(ctx context.Context, query RunQuery) ([]ScrapeRun, error)
+}
+
+// RedisRunLog keeps the runs in the scrape:runs Redis stream, capped to about
+// maxLen entries.
+type RedisRunLog struct {
+	client *redis.Client
+	maxLen int64
+}
+
+// NewRedisRunLog keeps about maxLen runs, 10000 when it is not positive.
+func NewRedisRunLog(client *redis.Client, maxLen int64) *RedisRunLog {
+	if maxLen <= 0 {
+		maxLen = defaultRunLogMaxLen
+	}
+
+	return &RedisRunLog{
+		client: client,
+		maxLen: maxLen,
+	}
+}
+
+func (l *RedisRunLog) Record(ctx context.Context, run ScrapeRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	return l.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: runStream,
+		MaxLen: l.maxLen,
+		Approx: true,
+		Values: map[string]any{
+			"source": run.Source,
+			"run":    string(data),
+		},
+	}).Err()
+}
+
+func (l *RedisRunLog) List(ctx context.Context, query RunQuery) ([]ScrapeRun, error) {
+	limit := query.limit()
+	runs := make([]ScrapeRun, 0, limit)
+
+	// filtered queries read the stream in batches until enough runs match
+	end := "+"
+
+	for len(runs) < limit {
+		messages, err := l.client.XRevRangeN(ctx, runStream, end, "-", int64(limit)).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, message := range messages {
+			data, _ := message.Values["run"].(string)
+
+			var run ScrapeRun
+			if err := json.Unmarshal([]byte(data), &run); err != nil {
+				continue
+			}
+
+			if query.matches(run) && len(runs) < limit {
+				runs = append(runs, run)
+			}
+		}
+
+		if len(messages) < limit {
+			break
+		}
+
+		end = "(" + messages[len(messages)-1].ID
+	}
+
+	return runs, nil
+}
+
+// WithRunLog records every scrape run in runs.
+func WithRunLog(runs RunLog) Option {
+	return func(o *scrapperOptions) {
+		o.runs = runs
+	}
+}
+
+// NormalizeFilter returns the canonical form of filter as a query string,
+// without the unset bounds, so equal searches read the same in the records.
+func NormalizeFilter(filter dtos.AutoFilter) string {
+	filter = canonicalFilter(filter)
+	values := url.Values{}
+
+	if filter.Brand != "" {
+		values.Set(FilterBrand, filter.Brand)
+	}
+
+	if filter.Model != "" {
+		values.Set(FilterModel, filter.Model)
+	}
+
+	for name, bound := range map[string]*uint32{FilterMinYear: filter.MinYear, FilterMaxYear: filter.MaxYear} {
+		if bound != nil {
+			values.Set(name, strconv.FormatUint(uint64(*bound), 10))
+		}
+	}
+
+	for name, bound := range map[string]*float64{FilterMinPrice: filter.MinPrice, FilterMaxPrice: filter.MaxPrice} {
+		if bound != nil {
+			values.Set(name, strconv.FormatFloat(*bound, 'f', -1, 64))
+		}
+	}
+
+	return values.Encode()
+}
+
+// ErrorClass classifies the error a scrape failed with.
+func ErrorClass(err error) string {
+	var netErr net.Error
+	var statusErr *statusError
+	var urlErr *url.Error
+
+	switch {
+	case err == nil:
+		return ErrorClassNone
+	case errors.Is(err, ErrBlocked):
+		return ErrorClassBlocked
+	case errors.Is(err, ErrResultsNotFound):
+		return ErrorClassLayoutChanged
+	case errors.Is(err, ErrBrowserUnavailable):
+		return ErrorClassBrowserUnavailable
+	case errors.Is(err, ErrNoProxyAvailable):
+		return ErrorClassNoProxy
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case errors.As(err, &statusErr), errors.As(err, &urlErr):
+		return ErrorClassFetch
+	}
+
+	return ErrorClassUnknown
+}
+
+// retryDelays are the waits suggested before retrying a scrape that failed
+// with an error class. Layout changes and unknown failures are not worth
+// retrying until they are fixed.
+var retryDelays = map[string]time.Duration{
+	ErrorClassBlocked:            defaultBlockCooldown,
+	ErrorClassBrowserUnavailable: 30 * time.Second,
+	ErrorClassNoProxy:            time.Minute,
+	ErrorClassTimeout:            10 * time.Second,
+	ErrorClassFetch:              30 * time.Second,
+}
+
+// RetryDelay returns how long to wait before retrying a scrape that failed
+// with err, or false when retrying will not help. Blocked sources are
+// retried after their cooldown.
+func RetryDelay(err error) (time.Duration, bool) {
+	var blockedErr *BlockedError
+	if errors.As(err, &blockedErr) && blockedErr.RetryAfter > 0 {
+		return blockedErr.RetryAfter, true
+	}
+
+	delay, ok := retryDelays[ErrorClass(err)]
+
+	return delay, ok
+}
+
+// scrapeRun tracks a run while it scrapes.
+type scrapeRun struct {
+	ScrapeRun
+
+	bytes atomic.Int64
+}
+
+// startRun starts the run of a scrape of source with backend.
+func (o scrapperOptions) startRun(source string, backend string, filter dtos.AutoFilter) *scrapeRun {
+	return &scrapeRun{
+		ScrapeRun: ScrapeRun{
+			ID:        newScrapeID(),
+			Source:    source,
+			Backend:   backend,
+			Filter:    NormalizeFilter(filter),
+			StartedAt: time.Now(),
+		},
+	}
+}
+
+// useProxy records the proxy of the run, without its credentials.
+func (r *scrapeRun) useProxy(proxy *Proxy) {
+	if proxy != nil {
+		r.Proxy = proxy.URL.Redacted()
+	}
+}
+
+// trackBytes adds the bytes page receives to the run. It must run before the
+// page navigates; the returned function stops it.
+func (r *scrapeRun) trackBytes(page *rod.Page) func() {
+	if err := (proto.NetworkEnable{}).Call(page); err != nil {
+		return func() {}
+	}
+
+	events, cancel := page.WithCancel()
+
+	go events.EachEvent(func(e *proto.NetworkLoadingFinished) {
+		r.bytes.Add(int64(e.EncodedDataLength))
+	})()
+
+	return cancel
+}
+
+// finishRun completes run with the extraction counts, the listings found and
+// the error of the scrape, and records it. Without a run log the record is
+// logged. It returns err as a ScrapeError with the run ID.
+func (o scrapperOptions) finishRun(run *scrapeRun, e *extraction, found int, err error) error {
+	run.EndedAt = time.Now()
+	run.Bytes = run.bytes.Load()
+	run.ErrorClass = ErrorClass(err)
+
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	run.Found = found
+
+	if e != nil {
+		run.Seen = e.seen
+
+		for _, count := range e.skipped {
+			run.Skipped += count
+		}
+	}
+
+	if o.runs == nil {
+		data, _ := json.Marshal(run.ScrapeRun)
+		log.Println("scrape run", string(data))
+
+		return scrapeError(run.ID, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), runRecordTimeout)
+	defer cancel()
+
+	if err := o.runs.Record(ctx, run.ScrapeRun); err != nil {
+		log.Println(run.Source, "recording scrape run", run.ID, "failed:", err)
+	}
+
+	return scrapeError(run.ID, err)
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/enums"
+)
+
+// memoryRunLog keeps the runs in memory, for tests.
+type memoryRunLog struct {
+	mu   sync.Mutex
+	runs []ScrapeRun
+}
+
+func (l *memoryRunLog) Record(ctx context.Context, run ScrapeRun) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.runs = append(l.runs, run)
+
+	return nil
+}
+
+func (l *memoryRunLog) List(ctx context.Context, query RunQuery) ([]ScrapeRun, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var runs []ScrapeRun
+	for i := len(l.runs) - 1; i >= 0 && len(runs) < query.limit(); i-- {
+		if query.matches(l.runs[i]) {
+			runs = append(runs, l.runs[i])
+		}
+	}
+
+	return runs, nil
+}
+
+func TestHTTPScrapperRecordsRun(t *testing.T) {
+	profiles := newTestProfileStore(t)
+	srv := newFixtureServer(t)
+	runs := &memoryRunLog{}
+
+	s := NewHTTPScrapper(profiles, enums.NeoAuto.String(), WithBaseURL(srv.URL+"/"), WithRunLog(runs))
+
+	autos, err := s.FindByFilter(context.Background(), dtos.AutoFilter{})
+	if err != nil {
+		t.Fatalf("FindByFilter() error = %v", err)
+	}
+
+	recorded, _ := runs.List(context.Background(), RunQuery{Source: "neoauto"})
+	if len(recorded) != 1 {
+		t.Fatalf("recorded %d runs, want 1", len(recorded))
+	}
+
+	run := recorded[0]
+	if run.ID == "" || run.Backend != BackendHTTP || run.URL != srv.URL+"/venta-de-autos-usados" {
+		t.Errorf("run = %+v, want an ID, the http backend and the search URL", run)
+	}
+
+	if run.Pages != 1 || run.Found != len(autos) || run.Skipped != 1 || run.Bytes == 0 {
+		t.Errorf("run counts = %d pages, %d found, %d skipped, %d bytes, want 1, %d, 1 and some bytes",
+			run.Pages, run.Found, run.Skipped, run.Bytes, len(autos))
+	}
+
+	if run.ErrorClass != ErrorClassNone || run.EndedAt.Before(run.StartedAt) {
+		t.Errorf("run = %+v, want a successful run ending after it started", run)
+	}
+}
+
+func TestNormalizeFilter(t *testing.T) {
+	got := NormalizeFilter(dtos.AutoFilter{
+		Brand:    " Toyota",
+		Model:    "YARIS",
+		MinYear:  uint32Ptr(2015),
+		MaxYear:  uint32Ptr(0),
+		MinPrice: float64Ptr(0),
+		MaxPrice: float64Ptr(20000.5),
+	})
+
+	if want := "brand=toyota&max_price=20000.5&min_year=2015&model=yaris"; got != want {
+		t.Errorf("NormalizeFilter() = %q, want %q", got, want)
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{err: nil, want: ErrorClassNone},
+		{err: &ScrapeError{ScrapeID: "abc", Err: &BlockedError{Source: "Test", Kind: BlockCaptcha}}, want: ErrorClassBlocked},
+		{err: fmt.Errorf("%w: .results", ErrResultsNotFound), want: ErrorClassLayoutChanged},
+		{err: fmt.Errorf("%w: launcher not found", ErrBrowserUnavailable), want: ErrorClassBrowserUnavailable},
+		{err: ErrNoProxyAvailable, want: ErrorClassNoProxy},
+		{err: context.DeadlineExceeded, want: ErrorClassTimeout},
+		{err: &statusError{code: 500, url: "https://autos.test"}, want: ErrorClassFetch},
+		{err: errors.New("boom"), want: ErrorClassUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := ErrorClass(tt.err); got != tt.want {
+			t.Errorf("ErrorClass(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		err       error
+		want      time.Duration
+		wantRetry bool
+	}{
+		{err: &ScrapeError{ScrapeID: "abc", Err: &BlockedError{Source: "Test", Kind: BlockCaptcha, RetryAfter: 5 * time.Minute}}, want: 5 * time.Minute, wantRetry: true},
+		{err: fmt.Errorf("Test: %w", ErrBlocked), want: defaultBlockCooldown, wantRetry: true},
+		{err: fmt.Errorf("%w: launcher not found", ErrBrowserUnavailable), want: 30 * time.Second, wantRetry: true},
+		{err: fmt.Errorf("%w: .results", ErrResultsNotFound)},
+		{err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		got, retry := RetryDelay(tt.err)
+		if got != tt.want || retry != tt.wantRetry {
+			t.Errorf("RetryDelay(%v) = %s, %v, want %s, %v", tt.err, got, retry, tt.want, tt.wantRetry)
+		}
+	}
+}
+
+func TestRegistryFailureCarriesSourcesAndScrapeIDs(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(enums.NeoAuto, scrapperFunc(func(filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+		options := newScrapperOptions(nil)
+		run := options.startRun("neoauto", BackendRod, filter)
+
+		return nil, options.finishRun(run, nil, 0, ErrResultsNotFound)
+	}))
+	registry.Register(enums.MercadoLibre, scrapperFunc(func(filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+		return nil, &BlockedError{Source: "mercadolibre", Kind: BlockRateLimited}
+	}))
+
+	_, err := registry.FindByFilter(context.Background(), dtos.AutoFilter{})
+
+	failures := SourceErrors(err)
+	if len(failures) != 2 {
+		t.Fatalf("SourceErrors() = %v, want both sources", failures)
+	}
+
+	if id, ok := ScrapeID(failures[0].Err); !ok || id == "" || !errors.Is(failures[0].Err, ErrResultsNotFound) {
+		t.Errorf("failure of %s = %v, want results not found with a scrape ID", failures[0].Source, failures[0].Err)
+	}
+
+	if !errors.Is(failures[1].Err, ErrBlocked) {
+		t.Errorf("failure of %s = %v, want blocked", failures[1].Source, failures[1].Err)
+	}
+}
+
+func TestRegistryReturnsPartialResults(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(enums.NeoAuto, scrapperFunc(func(filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+		return []*dtos.AutoFilterResponse{{Title: "Toyota Yaris", URL: "https://neoauto.test/yaris"}}, nil
+	}))
+	registry.Register(enums.MercadoLibre, scrapperFunc(func(filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+		return nil, &BlockedError{Source: "mercadolibre", Kind: BlockRateLimited}
+	}))
+
+	autos, err := registry.FindByFilter(context.Background(), dtos.AutoFilter{})
+	if !errors.Is(err, ErrPartialResults) {
+		t.Fatalf("FindByFilter() error = %v, want %v", err, ErrPartialResults)
+	}
+
+	if len(autos) != 1 {
+		t.Errorf("FindByFilter() = %d autos, want the one of the source that answered", len(autos))
+	}
+
+	failures := SourceErrors(err)
+	if len(failures) != 1 || failures[0].Source != enums.MercadoLibre.String() || !errors.Is(failures[0].Err, ErrBlocked) {
+		t.Errorf("SourceErrors() = %v, want the blocked Mercado Libre source", failures)
+	}
+}
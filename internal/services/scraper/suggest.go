@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	vehiclesSeenKey          = "vehicles:seen"
+	defaultSuggestionsWindow = 14 * 24 * time.Hour
+	defaultSuggestionsLimit  = 10
+	maxSuggestionsLimit      = 50
+	suggestionsTimeout       = 10 * time.Second
+)
+
+// SeenListing is a listing of a catalog model found by a scrape.
+type SeenListing struct {
+	URL    string
+	Brand  string
+	Model  string
+	SeenAt time.Time
+}
+
+// SeenStore counts the listings of every catalog model the scrapes find.
+type SeenStore interface {
+	Record(ctx context.Context, listings []SeenListing) error
+	// Counts returns the number of listings seen since then, by brand and
+	// model.
+	Counts(ctx context.Context, since time.Time) (map[VehicleKey]int, error)
+}
+
+// VehicleKey names a catalog brand and model; Model is empty for listings
+// of the brand without a known model.
+type VehicleKey struct {
+	Brand string
+	Model string
+}
+
+// RedisSeenStore keeps the last time each listing was seen in the
+// vehicles:seen Redis hash, by URL. Listings not seen since the counted
+// period are dropped while counting.
+type RedisSeenStore struct {
+	client *redis.Client
+}
+
+func NewRedisSeenStore(client *redis.Client) *RedisSeenStore {
+	return &RedisSeenStore{client: client}
+}
+
+func (s *RedisSeenStore) Record(ctx context.Context, listings []SeenListing) error {
+	if len(listings) == 0 {
+		return nil
+	}
+
+	values := make([]any, 0, 2*len(listings))
+
+	for _, listing := range listings {
+		values = append(values, listing.URL, strings.Join([]string{
+			listing.Brand,
+			listing.Model,
+			strconv.FormatInt(listing.SeenAt.Unix(), 10),
+		}, "\t"))
+	}
+
+	return s.client.HSet(ctx, vehiclesSeenKey, values...).Err()
+}
+
+func (s *RedisSeenStore) Counts(ctx context.Context, since time.Time) (map[VehicleKey]int, error) {
+	values, err := s.client.HGetAll(ctx, vehiclesSeenKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[VehicleKey]int)
+
+	var stale []string
+
+	for url, value := range values {
+		parts := strings.Split(value, "\t")
+		if len(parts) != 3 {
+			stale = append(stale, url)
+			continue
+		}
+
+		seenAt, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || time.Unix(seenAt, 0).Before(since) {
+			stale = append(stale, url)
+			continue
+		}
+
+		counts[VehicleKey{Brand: parts[0], Model: parts[1]}]++
+	}
+
+	if len(stale) > 0 {
+		if err := s.client.HDel(ctx, vehiclesSeenKey, stale...).Err(); err != nil {
+			log.Println("dropping stale seen listings failed:", err)
+		}
+	}
+
+	return counts, nil
+}
+
+// VehicleSuggestion is a brand, or a model of it, matching a prefix typed
+// by a user, with the listings seen of it.
+type VehicleSuggestion struct {
+	Brand    string
+	Model    string
+	Listings int
+	Score    float64
+}
+
+// suggestionEntry is a brand or model of the index, with the keys of every
+// way to write it: its name and aliases, models also after the brand.
+type suggestionEntry struct {
+	brand    string
+	model    string
+	keys     []string
+	listings int
+}
+
+// VehicleSuggester suggests catalog brands and models for what a user is
+// typing, ranked by how well they match and how many listings were seen of
+// them lately. It answers from an index in memory, refreshed from the
+// listing counts in a SeenStore, so suggestions never wait on a scrape.
+type VehicleSuggester struct {
+	catalog *Catalog
+	store   SeenStore
+	window  time.Duration
+
+	mu      sync.RWMutex
+	entries []suggestionEntry
+}
+
+// NewVehicleSuggester counts the listings seen in the last window, 14 days
+// when it is not positive. Until the first Refresh every count is zero.
+func NewVehicleSuggester(catalog *Catalog, store SeenStore, window time.Duration) *VehicleSuggester {
+	if window <= 0 {
+		window = defaultSuggestionsWindow
+	}
+
+	s := &VehicleSuggester{
+		catalog: catalog,
+		store:   store,
+		window:  window,
+	}
+
+	s.entries = s.index(nil)
+
+	return s
+}
+
+// Wrap returns scrapper, counting the catalog models of the listings it
+// finds.
+func (s *VehicleSuggester) Wrap(scrapper AutoScrapper) AutoScrapper {
+	return &countedScrapper{
+		suggester: s,
+		scrapper:  scrapper,
+	}
+}
+
+// countedScrapper records the listings of a scrapper in the background.
+type countedScrapper struct {
+	suggester *VehicleSuggester
+	scrapper  AutoScrapper
+}
+
+func (s *countedScrapper) FindByFilter(ctx context.Context, filter dtos.AutoFilter) ([]*dtos.AutoFilterResponse, error) {
+	autos, err := s.scrapper.FindByFilter(ctx, filter)
+
+	if len(autos) > 0 {
+		seen := s.suggester.seen(autos)
+
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), suggestionsTimeout)
+			defer cancel()
+
+			if err := s.suggester.store.Record(ctx, seen); err != nil {
+				log.Println("recording seen listings failed:", err)
+			}
+		}()
+	}
+
+	return autos, err
+}
+
+// seen resolves the catalog brand and model of autos, from their attributes
+// or their titles.
+func (s *VehicleSuggester) seen(autos []*dtos.AutoFilterResponse) []SeenListing {
+	now := time.Now()
+	seen := make([]SeenListing, 0, len(autos))
+
+	for _, auto := range autos {
+		match, ok := s.catalog.ResolveTitle(auto.Title)
+		if auto.Brand != "" {
+			match, ok = s.catalog.Resolve(auto.Brand, auto.Model)
+		}
+
+		if !ok {
+			continue
+		}
+
+		listing := SeenListing{URL: auto.URL, Brand: match.Brand.Name, SeenAt: now}
+		if match.Model != nil {
+			listing.Model = match.Model.Name
+		}
+
+		seen = append(seen, listing)
+	}
+
+	return seen
+}
+
+// Watch refreshes the index now and then every interval, until ctx is done.
+func (s *VehicleSuggester) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(ctx); err != nil {
+			log.Println("refreshing vehicle suggestions failed:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh rebuilds the index with the current listing counts.
+func (s *VehicleSuggester) Refresh(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, suggestionsTimeout)
+	defer cancel()
+
+	counts, err := s.store.Counts(ctx, time.Now().Add(-s.window))
+	if err != nil {
+		return err
+	}
+
+	entries := s.index(counts)
+
+	s.mu.Lock()
+	s.entries = entries
+	s.mu.Unlock()
+
+	return nil
+}
+
+// index builds the entries of every catalog brand and model with their
+// listing counts; brands count the listings of all their models.
+func (s *VehicleSuggester) index(counts map[VehicleKey]int) []suggestionEntry {
+	var entries []suggestionEntry
+
+	for _, brand := range s.catalog.Brands {
+		brandNames := append([]string{brand.Name}, brand.Aliases...)
+
+		brandEntry := suggestionEntry{
+			brand:    brand.Name,
+			keys:     catalogKeys(brandNames),
+			listings: counts[VehicleKey{Brand: brand.Name}],
+		}
+
+		for _, model := range brand.Models {
+			modelNames := append([]string{model.Name}, model.Aliases...)
+
+			entry := suggestionEntry{
+				brand:    brand.Name,
+				model:    model.Name,
+				keys:     catalogKeys(modelNames),
+				listings: counts[VehicleKey{Brand: brand.Name, Model: model.Name}],
+			}
+
+			for _, brandName := range brandNames {
+				for _, modelName := range modelNames {
+					entry.keys = append(entry.keys, catalogKey(brandName+modelName))
+				}
+			}
+
+			brandEntry.listings += entry.listings
+			entries = append(entries, entry)
+		}
+
+		entries = append(entries, brandEntry)
+	}
+
+	return entries
+}
+
+func catalogKeys(names []string) []string {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, catalogKey(name))
+	}
+
+	return keys
+}
+
+// Suggest returns at most limit brands and models matching prefix, best
+// first. Prefixes match ignoring case, accents, spaces and punctuation, with
+// a typo allowed from four characters on and two from eight. Scores weigh
+// the match by the logarithm of the listings seen, so common models come
+// before rare ones and typos after exact matches of similar counts. An
+// empty prefix suggests the brands with the most listings.
+func (s *VehicleSuggester) Suggest(prefix string, limit int) []VehicleSuggestion {
+	if limit <= 0 {
+		limit = defaultSuggestionsLimit
+	}
+
+	limit = min(limit, maxSuggestionsLimit)
+	key := catalogKey(prefix)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var suggestions []VehicleSuggestion
+
+	for _, entry := range s.entries {
+		if key == "" && entry.model != "" {
+			continue
+		}
+
+		match := prefixMatch(entry.keys, key)
+		if match == 0 {
+			continue
+		}
+
+		suggestions = append(suggestions, VehicleSuggestion{
+			Brand:    entry.brand,
+			Model:    entry.model,
+			Listings: entry.listings,
+			Score:    match * (1 + math.Log1p(float64(entry.listings))),
+		})
+	}
+
+	slices.SortStableFunc(suggestions, func(a, b VehicleSuggestion) int {
+		switch {
+		case a.Score != b.Score:
+			if a.Score > b.Score {
+				return -1
+			}
+
+			return 1
+		case a.Brand != b.Brand:
+			return strings.Compare(a.Brand, b.Brand)
+		}
+
+		return strings.Compare(a.Model, b.Model)
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions
+}
+
+// prefixMatch scores how well the best of keys starts with prefix: 1 when
+// it does, less for every typo, 0 when none is close enough.
+func prefixMatch(keys []string, prefix string) float64 {
+	if prefix == "" {
+		return 1
+	}
+
+	n := len([]rune(prefix))
+	typos := allowedTypos(prefix)
+	best := 0.0
+
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			return 1
+		}
+
+		if typos == 0 {
+			continue
+		}
+
+		// the prefix may miss or add a character to the start of the key
+		runes := []rune(key)
+
+		for _, length := range []int{n - 1, n, n + 1} {
+			if length <= 0 || length > len(runes) {
+				continue
+			}
+
+			if distance := levenshtein(prefix, string(runes[:length])); distance <= typos {
+				best = max(best, 1-float64(distance)/float64(n))
+			}
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diegoafg1009/auto-radar-scraping-microservice/internal/dtos"
+)
+
+// memorySeenStore keeps the seen listings in memory, for tests.
+type memorySeenStore struct {
+	mu       sync.Mutex
+	listings map[string]SeenListing
+}
+
+func newMemorySeenStore() *memorySeenStore {
+	return &memorySeenStore{listings: make(map[string]SeenListing)}
+}
+
+func (s *memorySeenStore) Record(ctx context.Context, listings []SeenListing) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, listing := range listings {
+		s.listings[listing.URL] = listing
+	}
+
+	return nil
+}
+
+func (s *memorySeenStore) Counts(ctx context.Context, since time.Time) (map[VehicleKey]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[VehicleKey]int)
+	for _, listing := range s.listings {
+		if !listing.SeenAt.Before(since) {
+			counts[VehicleKey{Brand: listing.Brand, Model: listing.Model}]++
+		}
+	}
+
+	return counts, nil
+}
+
+func TestVehicleSuggesterSuggest(t *testing.T) {
+	catalog, err := LoadCatalog("")
+	if err != nil {
+		t.Fatalf("LoadCatalog() error = %v", err)
+	}
+
+	store := newMemorySeenStore()
+	suggester := NewVehicleSuggester(catalog, store, time.Hour)
+
+	autos := []*dtos.AutoFilterResponse{
+		{URL: "https://example.com/1", Title: "Toyota Corolla Cross 2022 híbrido"},
+		{URL: "https://example.com/2", Title: "Toyota Corolla Cross XLI"},
+		{URL: "https://example.com/3", Title: "Toyota Corolla 2018"},
+		{URL: "https://example.com/4", Brand: "Kia", Model: "Río", Title: "Sedán full equipo"},
+		{URL: "https://example.com/5", Title: "Se vende auto"},
+	}
+
+	if err := store.Record(context.Background(), suggester.seen(autos)); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	store.listings["https://example.com/old"] = SeenListing{
+		URL:    "https://example.com/old",
+		Brand:  "Toyota",
+		Model:  "Corolla",
+		SeenAt: time.Now().Add(-2 * time.Hour),
+	}
+
+	if err := suggester.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	tests := []struct {
+		prefix    string
+		wantBrand string
+		wantModel string
+		listings  int
+	}{
+		{prefix: "coro", wantBrand: "Toyota", wantModel: "Corolla Cross", listings: 2},
+		{prefix: "toyota cor", wantBrand: "Toyota", wantModel: "Corolla Cross", listings: 2},
+		{prefix: "corola", wantBrand: "Toyota", wantModel: "Corolla Cross", listings: 2},
+		{prefix: "hilx", wantBrand: "Toyota", wantModel: "Hilux"},
+		{prefix: "RÍO", wantBrand: "Kia", wantModel: "Rio", listings: 1},
+		{prefix: "toyo", wantBrand: "Toyota", listings: 3},
+		{prefix: "", wantBrand: "Toyota", listings: 3},
+	}
+
+	for _, tt := range tests {
+		suggestions := suggester.Suggest(tt.prefix, 3)
+		if len(suggestions) == 0 {
+			t.Errorf("Suggest(%q) is empty", tt.prefix)
+			continue
+		}
+
+		got := suggestions[0]
+		if got.Brand != tt.wantBrand || got.Model != tt.wantModel || got.Listings != tt.listings {
+			t.Errorf("Suggest(%q)[0] = %+v, want %s %s with %d listings", tt.prefix, got, tt.wantBrand, tt.wantModel, tt.listings)
+		}
+	}
+
+	if suggestions := suggester.Suggest("", 0); len(suggestions) != defaultSuggestionsLimit {
+		t.Errorf("Suggest(\"\", 0) returned %d suggestions, want %d", len(suggestions), defaultSuggestionsLimit)
+	}
+
+	for _, suggestion := range suggester.Suggest("", maxSuggestionsLimit) {
+		if suggestion.Model != "" {
+			t.Errorf("Suggest(\"\") suggested model %s %s", suggestion.Brand, suggestion.Model)
+		}
+	}
+
+	if suggestions := suggester.Suggest("xyzw", 10); len(suggestions) != 0 {
+		t.Errorf("Suggest(%q) = %+v, want none", "xyzw", suggestions)
+	}
+}
@@ -9,6 +9,8 @@ package autoscrapperv1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,6 +23,16 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Invalid filters fail with InvalidArgument and a BadRequest detail naming
+// the invalid fields. Years are from 1900 on and 0 leaves a bound unset.
+//
+// Searches where every source failed are Unavailable when worth retrying,
+// Internal otherwise, with an ErrorInfo detail per source (reason
+// SOURCE_BLOCKED, LAYOUT_CHANGED, BROWSER_UNAVAILABLE, NO_PROXY_AVAILABLE,
+// SOURCE_TIMEOUT, SOURCE_UNREACHABLE or SCRAPE_FAILED, metadata source and
+// scrape_id) and a RetryInfo detail with the suggested backoff. Searches
+// where only some sources failed answer with the listings of the others and
+// the failures in the response.
 type FindByFilterRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Brand         string                 `protobuf:"bytes,1,opt,name=brand,proto3" json:"brand,omitempty"`
@@ -106,13 +118,23 @@ func (x *FindByFilterRequest) GetMaxPrice() float64 {
 }
 
 type Auto struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
-	Price         float64                `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
-	ImageUrl      string                 `protobuf:"bytes,3,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
-	Url           string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Title     string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Price     float64                `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	ImageUrl  string                 `protobuf:"bytes,3,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+	Url       string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	ImageUrls []string               `protobuf:"bytes,5,rep,name=image_urls,json=imageUrls,proto3" json:"image_urls,omitempty"`
+	// Only set by the sources that publish them, e.g. MercadoLibre.
+	Brand      string `protobuf:"bytes,6,opt,name=brand,proto3" json:"brand,omitempty"`
+	Model      string `protobuf:"bytes,7,opt,name=model,proto3" json:"model,omitempty"`
+	Year       uint32 `protobuf:"varint,8,opt,name=year,proto3" json:"year,omitempty"`
+	Kilometers uint32 `protobuf:"varint,9,opt,name=kilometers,proto3" json:"kilometers,omitempty"`
+	// Served by this service, set when its image cache is enabled.
+	CachedImageUrl  string   `protobuf:"bytes,10,opt,name=cached_image_url,json=cachedImageUrl,proto3" json:"cached_image_url,omitempty"`
+	CachedImageUrls []string `protobuf:"bytes,11,rep,name=cached_image_urls,json=cachedImageUrls,proto3" json:"cached_image_urls,omitempty"`
+	ThumbnailUrl    string   `protobuf:"bytes,12,opt,name=thumbnail_url,json=thumbnailUrl,proto3" json:"thumbnail_url,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *Auto) Reset() {
@@ -173,9 +195,70 @@ func (x *Auto) GetUrl() string {
 	return ""
 }
 
+func (x *Auto) GetImageUrls() []string {
+	if x != nil {
+		return x.ImageUrls
+	}
+	return nil
+}
+
+func (x *Auto) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *Auto) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Auto) GetYear() uint32 {
+	if x != nil {
+		return x.Year
+	}
+	return 0
+}
+
+func (x *Auto) GetKilometers() uint32 {
+	if x != nil {
+		return x.Kilometers
+	}
+	return 0
+}
+
+func (x *Auto) GetCachedImageUrl() string {
+	if x != nil {
+		return x.CachedImageUrl
+	}
+	return ""
+}
+
+func (x *Auto) GetCachedImageUrls() []string {
+	if x != nil {
+		return x.CachedImageUrls
+	}
+	return nil
+}
+
+func (x *Auto) GetThumbnailUrl() string {
+	if x != nil {
+		return x.ThumbnailUrl
+	}
+	return ""
+}
+
 type FindByFilterResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Autos         []*Auto                `protobuf:"bytes,1,rep,name=autos,proto3" json:"autos,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Autos []*Auto                `protobuf:"bytes,1,rep,name=autos,proto3" json:"autos,omitempty"`
+	// Sources that failed while the others answered.
+	Failures []*SourceFailure `protobuf:"bytes,2,rep,name=failures,proto3" json:"failures,omitempty"`
+	// Browser scrapes that found nothing, with the scrape ID of their
+	// artifacts.
+	EmptyScrapes  []*EmptyScrape `protobuf:"bytes,3,rep,name=empty_scrapes,json=emptyScrapes,proto3" json:"empty_scrapes,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -217,27 +300,1891 @@ func (x *FindByFilterResponse) GetAutos() []*Auto {
 	return nil
 }
 
-var File_autoscrapper_v1_autoscrapper_proto protoreflect.FileDescriptor
+func (x *FindByFilterResponse) GetFailures() []*SourceFailure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
 
-const file_autoscrapper_v1_autoscrapper_proto_rawDesc = "" +
-	"\n" +
-	"\"autoscrapper/v1/autoscrapper.proto\x12\x0fautoscrapper.v1\"\xb1\x01\n" +
-	"\x13FindByFilterRequest\x12\x14\n" +
-	"\x05brand\x18\x01 \x01(\tR\x05brand\x12\x14\n" +
-	"\x05model\x18\x02 \x01(\tR\x05model\x12\x19\n" +
-	"\bmin_year\x18\x03 \x01(\rR\aminYear\x12\x19\n" +
-	"\bmax_year\x18\x04 \x01(\rR\amaxYear\x12\x1b\n" +
-	"\tmin_price\x18\x05 \x01(\x01R\bminPrice\x12\x1b\n" +
-	"\tmax_price\x18\x06 \x01(\x01R\bmaxPrice\"a\n" +
-	"\x04Auto\x12\x14\n" +
-	"\x05title\x18\x01 \x01(\tR\x05title\x12\x14\n" +
-	"\x05price\x18\x02 \x01(\x01R\x05price\x12\x1b\n" +
-	"\timage_url\x18\x03 \x01(\tR\bimageUrl\x12\x10\n" +
-	"\x03url\x18\x04 \x01(\tR\x03url\"C\n" +
-	"\x14FindByFilterResponse\x12+\n" +
-	"\x05autos\x18\x01 \x03(\v2\x15.autoscrapper.v1.AutoR\x05autos2t\n" +
-	"\x13AutoScrapperService\x12]\n" +
-	"\fFindByFilter\x12$.autoscrapper.v1.FindByFilterRequest\x1a%.autoscrapper.v1.FindByFilterResponse\"\x00B\xeb\x01\n" +
+func (x *FindByFilterResponse) GetEmptyScrapes() []*EmptyScrape {
+	if x != nil {
+		return x.EmptyScrapes
+	}
+	return nil
+}
+
+// The ErrorInfo and RetryInfo of a source failing in a search where others
+// answered.
+type SourceFailure struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Source string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// ErrorInfo reason, in the autoscrapper.v1 domain.
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Empty when the scrape was not recorded.
+	ScrapeId string `protobuf:"bytes,3,opt,name=scrape_id,json=scrapeId,proto3" json:"scrape_id,omitempty"`
+	// Unset when the source is not worth retrying.
+	RetryDelay    *durationpb.Duration `protobuf:"bytes,4,opt,name=retry_delay,json=retryDelay,proto3" json:"retry_delay,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SourceFailure) Reset() {
+	*x = SourceFailure{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourceFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourceFailure) ProtoMessage() {}
+
+func (x *SourceFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourceFailure.ProtoReflect.Descriptor instead.
+func (*SourceFailure) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SourceFailure) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SourceFailure) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SourceFailure) GetScrapeId() string {
+	if x != nil {
+		return x.ScrapeId
+	}
+	return ""
+}
+
+func (x *SourceFailure) GetRetryDelay() *durationpb.Duration {
+	if x != nil {
+		return x.RetryDelay
+	}
+	return nil
+}
+
+type EmptyScrape struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	ScrapeId      string                 `protobuf:"bytes,2,opt,name=scrape_id,json=scrapeId,proto3" json:"scrape_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmptyScrape) Reset() {
+	*x = EmptyScrape{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmptyScrape) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmptyScrape) ProtoMessage() {}
+
+func (x *EmptyScrape) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmptyScrape.ProtoReflect.Descriptor instead.
+func (*EmptyScrape) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EmptyScrape) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *EmptyScrape) GetScrapeId() string {
+	if x != nil {
+		return x.ScrapeId
+	}
+	return ""
+}
+
+type GetScrapeQualityRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Empty for every source.
+	Source        string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetScrapeQualityRequest) Reset() {
+	*x = GetScrapeQualityRequest{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetScrapeQualityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetScrapeQualityRequest) ProtoMessage() {}
+
+func (x *GetScrapeQualityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetScrapeQualityRequest.ProtoReflect.Descriptor instead.
+func (*GetScrapeQualityRequest) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetScrapeQualityRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+type FieldQuality struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Found         uint64                 `protobuf:"varint,2,opt,name=found,proto3" json:"found,omitempty"`
+	Missing       uint64                 `protobuf:"varint,3,opt,name=missing,proto3" json:"missing,omitempty"`
+	Invalid       uint64                 `protobuf:"varint,4,opt,name=invalid,proto3" json:"invalid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldQuality) Reset() {
+	*x = FieldQuality{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldQuality) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldQuality) ProtoMessage() {}
+
+func (x *FieldQuality) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldQuality.ProtoReflect.Descriptor instead.
+func (*FieldQuality) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FieldQuality) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldQuality) GetFound() uint64 {
+	if x != nil {
+		return x.Found
+	}
+	return 0
+}
+
+func (x *FieldQuality) GetMissing() uint64 {
+	if x != nil {
+		return x.Missing
+	}
+	return 0
+}
+
+func (x *FieldQuality) GetInvalid() uint64 {
+	if x != nil {
+		return x.Invalid
+	}
+	return 0
+}
+
+type SkippedItems struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	Count         uint64                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkippedItems) Reset() {
+	*x = SkippedItems{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkippedItems) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkippedItems) ProtoMessage() {}
+
+func (x *SkippedItems) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkippedItems.ProtoReflect.Descriptor instead.
+func (*SkippedItems) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SkippedItems) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *SkippedItems) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type SourceQuality struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Source              string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Scrapes             uint64                 `protobuf:"varint,2,opt,name=scrapes,proto3" json:"scrapes,omitempty"`
+	Seen                uint64                 `protobuf:"varint,3,opt,name=seen,proto3" json:"seen,omitempty"`
+	Extracted           uint64                 `protobuf:"varint,4,opt,name=extracted,proto3" json:"extracted,omitempty"`
+	ExtractionRatio     float64                `protobuf:"fixed64,5,opt,name=extraction_ratio,json=extractionRatio,proto3" json:"extraction_ratio,omitempty"`
+	LastExtractionRatio float64                `protobuf:"fixed64,6,opt,name=last_extraction_ratio,json=lastExtractionRatio,proto3" json:"last_extraction_ratio,omitempty"`
+	Alerting            bool                   `protobuf:"varint,7,opt,name=alerting,proto3" json:"alerting,omitempty"`
+	Alerts              uint64                 `protobuf:"varint,8,opt,name=alerts,proto3" json:"alerts,omitempty"`
+	Fields              []*FieldQuality        `protobuf:"bytes,9,rep,name=fields,proto3" json:"fields,omitempty"`
+	Skipped             []*SkippedItems        `protobuf:"bytes,10,rep,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *SourceQuality) Reset() {
+	*x = SourceQuality{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SourceQuality) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SourceQuality) ProtoMessage() {}
+
+func (x *SourceQuality) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SourceQuality.ProtoReflect.Descriptor instead.
+func (*SourceQuality) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SourceQuality) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *SourceQuality) GetScrapes() uint64 {
+	if x != nil {
+		return x.Scrapes
+	}
+	return 0
+}
+
+func (x *SourceQuality) GetSeen() uint64 {
+	if x != nil {
+		return x.Seen
+	}
+	return 0
+}
+
+func (x *SourceQuality) GetExtracted() uint64 {
+	if x != nil {
+		return x.Extracted
+	}
+	return 0
+}
+
+func (x *SourceQuality) GetExtractionRatio() float64 {
+	if x != nil {
+		return x.ExtractionRatio
+	}
+	return 0
+}
+
+func (x *SourceQuality) GetLastExtractionRatio() float64 {
+	if x != nil {
+		return x.LastExtractionRatio
+	}
+	return 0
+}
+
+func (x *SourceQuality) GetAlerting() bool {
+	if x != nil {
+		return x.Alerting
+	}
+	return false
+}
+
+func (x *SourceQuality) GetAlerts() uint64 {
+	if x != nil {
+		return x.Alerts
+	}
+	return 0
+}
+
+func (x *SourceQuality) GetFields() []*FieldQuality {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *SourceQuality) GetSkipped() []*SkippedItems {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+type GetScrapeQualityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sources       []*SourceQuality       `protobuf:"bytes,1,rep,name=sources,proto3" json:"sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetScrapeQualityResponse) Reset() {
+	*x = GetScrapeQualityResponse{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetScrapeQualityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetScrapeQualityResponse) ProtoMessage() {}
+
+func (x *GetScrapeQualityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetScrapeQualityResponse.ProtoReflect.Descriptor instead.
+func (*GetScrapeQualityResponse) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetScrapeQualityResponse) GetSources() []*SourceQuality {
+	if x != nil {
+		return x.Sources
+	}
+	return nil
+}
+
+type ListScrapeRunsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Empty for every source.
+	Source string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// Empty for every run, e.g. "blocked" or "layout_changed".
+	ErrorClass string `protobuf:"bytes,2,opt,name=error_class,json=errorClass,proto3" json:"error_class,omitempty"`
+	// 20 when unset, at most 200.
+	Limit         uint32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScrapeRunsRequest) Reset() {
+	*x = ListScrapeRunsRequest{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScrapeRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScrapeRunsRequest) ProtoMessage() {}
+
+func (x *ListScrapeRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScrapeRunsRequest.ProtoReflect.Descriptor instead.
+func (*ListScrapeRunsRequest) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListScrapeRunsRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListScrapeRunsRequest) GetErrorClass() string {
+	if x != nil {
+		return x.ErrorClass
+	}
+	return ""
+}
+
+func (x *ListScrapeRunsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ScrapeRun struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Source      string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Backend     string                 `protobuf:"bytes,3,opt,name=backend,proto3" json:"backend,omitempty"`
+	Filter      string                 `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	Url         string                 `protobuf:"bytes,5,opt,name=url,proto3" json:"url,omitempty"`
+	StartedAt   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	EndedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=ended_at,json=endedAt,proto3" json:"ended_at,omitempty"`
+	Pages       uint32                 `protobuf:"varint,8,opt,name=pages,proto3" json:"pages,omitempty"`
+	Seen        uint32                 `protobuf:"varint,9,opt,name=seen,proto3" json:"seen,omitempty"`
+	Found       uint32                 `protobuf:"varint,10,opt,name=found,proto3" json:"found,omitempty"`
+	Skipped     uint32                 `protobuf:"varint,11,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Bytes       uint64                 `protobuf:"varint,12,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Proxy       string                 `protobuf:"bytes,13,opt,name=proxy,proto3" json:"proxy,omitempty"`
+	Fingerprint string                 `protobuf:"bytes,14,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	ErrorClass  string                 `protobuf:"bytes,15,opt,name=error_class,json=errorClass,proto3" json:"error_class,omitempty"`
+	Error       string                 `protobuf:"bytes,16,opt,name=error,proto3" json:"error,omitempty"`
+	// Scrape ID of the page artifacts, when the scrape failed or found
+	// nothing and they were saved.
+	ArtifactsId   string `protobuf:"bytes,17,opt,name=artifacts_id,json=artifactsId,proto3" json:"artifacts_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScrapeRun) Reset() {
+	*x = ScrapeRun{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScrapeRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScrapeRun) ProtoMessage() {}
+
+func (x *ScrapeRun) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScrapeRun.ProtoReflect.Descriptor instead.
+func (*ScrapeRun) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ScrapeRun) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ScrapeRun) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ScrapeRun) GetBackend() string {
+	if x != nil {
+		return x.Backend
+	}
+	return ""
+}
+
+func (x *ScrapeRun) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *ScrapeRun) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ScrapeRun) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *ScrapeRun) GetEndedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndedAt
+	}
+	return nil
+}
+
+func (x *ScrapeRun) GetPages() uint32 {
+	if x != nil {
+		return x.Pages
+	}
+	return 0
+}
+
+func (x *ScrapeRun) GetSeen() uint32 {
+	if x != nil {
+		return x.Seen
+	}
+	return 0
+}
+
+func (x *ScrapeRun) GetFound() uint32 {
+	if x != nil {
+		return x.Found
+	}
+	return 0
+}
+
+func (x *ScrapeRun) GetSkipped() uint32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *ScrapeRun) GetBytes() uint64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *ScrapeRun) GetProxy() string {
+	if x != nil {
+		return x.Proxy
+	}
+	return ""
+}
+
+func (x *ScrapeRun) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+func (x *ScrapeRun) GetErrorClass() string {
+	if x != nil {
+		return x.ErrorClass
+	}
+	return ""
+}
+
+func (x *ScrapeRun) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ScrapeRun) GetArtifactsId() string {
+	if x != nil {
+		return x.ArtifactsId
+	}
+	return ""
+}
+
+type ListScrapeRunsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Most recent first.
+	Runs          []*ScrapeRun `protobuf:"bytes,1,rep,name=runs,proto3" json:"runs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScrapeRunsResponse) Reset() {
+	*x = ListScrapeRunsResponse{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScrapeRunsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScrapeRunsResponse) ProtoMessage() {}
+
+func (x *ListScrapeRunsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScrapeRunsResponse.ProtoReflect.Descriptor instead.
+func (*ListScrapeRunsResponse) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListScrapeRunsResponse) GetRuns() []*ScrapeRun {
+	if x != nil {
+		return x.Runs
+	}
+	return nil
+}
+
+type ImportListingsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One listing per message. url, title and price are required.
+	Auto          *Auto `protobuf:"bytes,1,opt,name=auto,proto3" json:"auto,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportListingsRequest) Reset() {
+	*x = ImportListingsRequest{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportListingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportListingsRequest) ProtoMessage() {}
+
+func (x *ImportListingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportListingsRequest.ProtoReflect.Descriptor instead.
+func (*ImportListingsRequest) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ImportListingsRequest) GetAuto() *Auto {
+	if x != nil {
+		return x.Auto
+	}
+	return nil
+}
+
+type ImportRejection struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Position of the listing in the stream.
+	Index uint32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Url   string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// e.g. "price_missing" or "url_invalid".
+	Reason        string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportRejection) Reset() {
+	*x = ImportRejection{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportRejection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRejection) ProtoMessage() {}
+
+func (x *ImportRejection) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRejection.ProtoReflect.Descriptor instead.
+func (*ImportRejection) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ImportRejection) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ImportRejection) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ImportRejection) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ImportListingsResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Received uint32                 `protobuf:"varint,1,opt,name=received,proto3" json:"received,omitempty"`
+	// New URLs.
+	Imported uint32 `protobuf:"varint,2,opt,name=imported,proto3" json:"imported,omitempty"`
+	// URLs already imported, replaced.
+	Updated uint32 `protobuf:"varint,3,opt,name=updated,proto3" json:"updated,omitempty"`
+	// URLs repeated in the stream, only the first is kept.
+	Duplicates    uint32             `protobuf:"varint,4,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	Rejected      []*ImportRejection `protobuf:"bytes,5,rep,name=rejected,proto3" json:"rejected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportListingsResponse) Reset() {
+	*x = ImportListingsResponse{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportListingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportListingsResponse) ProtoMessage() {}
+
+func (x *ImportListingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportListingsResponse.ProtoReflect.Descriptor instead.
+func (*ImportListingsResponse) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ImportListingsResponse) GetReceived() uint32 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *ImportListingsResponse) GetImported() uint32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportListingsResponse) GetUpdated() uint32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *ImportListingsResponse) GetDuplicates() uint32 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+func (x *ImportListingsResponse) GetRejected() []*ImportRejection {
+	if x != nil {
+		return x.Rejected
+	}
+	return nil
+}
+
+type ListRemovedListingsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Empty for every source.
+	Source string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	// 20 when unset, at most 200.
+	Limit         uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRemovedListingsRequest) Reset() {
+	*x = ListRemovedListingsRequest{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRemovedListingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRemovedListingsRequest) ProtoMessage() {}
+
+func (x *ListRemovedListingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRemovedListingsRequest.ProtoReflect.Descriptor instead.
+func (*ListRemovedListingsRequest) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListRemovedListingsRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListRemovedListingsRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListingLifecycle struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Url    string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Source string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Title  string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	// Last price seen.
+	Price float64 `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
+	// "sold" or "removed".
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	FirstSeen     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastSeen      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	RemovedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=removed_at,json=removedAt,proto3" json:"removed_at,omitempty"`
+	DaysOnMarket  uint32                 `protobuf:"varint,9,opt,name=days_on_market,json=daysOnMarket,proto3" json:"days_on_market,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListingLifecycle) Reset() {
+	*x = ListingLifecycle{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListingLifecycle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListingLifecycle) ProtoMessage() {}
+
+func (x *ListingLifecycle) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListingLifecycle.ProtoReflect.Descriptor instead.
+func (*ListingLifecycle) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListingLifecycle) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ListingLifecycle) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListingLifecycle) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ListingLifecycle) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *ListingLifecycle) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListingLifecycle) GetFirstSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstSeen
+	}
+	return nil
+}
+
+func (x *ListingLifecycle) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *ListingLifecycle) GetRemovedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RemovedAt
+	}
+	return nil
+}
+
+func (x *ListingLifecycle) GetDaysOnMarket() uint32 {
+	if x != nil {
+		return x.DaysOnMarket
+	}
+	return 0
+}
+
+type ListRemovedListingsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Most recently removed first.
+	Listings      []*ListingLifecycle `protobuf:"bytes,1,rep,name=listings,proto3" json:"listings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRemovedListingsResponse) Reset() {
+	*x = ListRemovedListingsResponse{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRemovedListingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRemovedListingsResponse) ProtoMessage() {}
+
+func (x *ListRemovedListingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRemovedListingsResponse.ProtoReflect.Descriptor instead.
+func (*ListRemovedListingsResponse) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListRemovedListingsResponse) GetListings() []*ListingLifecycle {
+	if x != nil {
+		return x.Listings
+	}
+	return nil
+}
+
+type VehicleModel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Aliases       []string               `protobuf:"bytes,2,rep,name=aliases,proto3" json:"aliases,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VehicleModel) Reset() {
+	*x = VehicleModel{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VehicleModel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VehicleModel) ProtoMessage() {}
+
+func (x *VehicleModel) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VehicleModel.ProtoReflect.Descriptor instead.
+func (*VehicleModel) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *VehicleModel) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *VehicleModel) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+type VehicleBrand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Aliases       []string               `protobuf:"bytes,2,rep,name=aliases,proto3" json:"aliases,omitempty"`
+	Models        []*VehicleModel        `protobuf:"bytes,3,rep,name=models,proto3" json:"models,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VehicleBrand) Reset() {
+	*x = VehicleBrand{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VehicleBrand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VehicleBrand) ProtoMessage() {}
+
+func (x *VehicleBrand) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VehicleBrand.ProtoReflect.Descriptor instead.
+func (*VehicleBrand) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *VehicleBrand) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *VehicleBrand) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+func (x *VehicleBrand) GetModels() []*VehicleModel {
+	if x != nil {
+		return x.Models
+	}
+	return nil
+}
+
+type ListBrandsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBrandsRequest) Reset() {
+	*x = ListBrandsRequest{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBrandsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBrandsRequest) ProtoMessage() {}
+
+func (x *ListBrandsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBrandsRequest.ProtoReflect.Descriptor instead.
+func (*ListBrandsRequest) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{21}
+}
+
+type ListBrandsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// In catalog order, with their models.
+	Brands        []*VehicleBrand `protobuf:"bytes,1,rep,name=brands,proto3" json:"brands,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBrandsResponse) Reset() {
+	*x = ListBrandsResponse{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBrandsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBrandsResponse) ProtoMessage() {}
+
+func (x *ListBrandsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBrandsResponse.ProtoReflect.Descriptor instead.
+func (*ListBrandsResponse) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListBrandsResponse) GetBrands() []*VehicleBrand {
+	if x != nil {
+		return x.Brands
+	}
+	return nil
+}
+
+type ListModelsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// A name or alias, e.g. "VW".
+	Brand         string `protobuf:"bytes,1,opt,name=brand,proto3" json:"brand,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListModelsRequest) Reset() {
+	*x = ListModelsRequest{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListModelsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListModelsRequest) ProtoMessage() {}
+
+func (x *ListModelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListModelsRequest.ProtoReflect.Descriptor instead.
+func (*ListModelsRequest) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListModelsRequest) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+type ListModelsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Brand         string                 `protobuf:"bytes,1,opt,name=brand,proto3" json:"brand,omitempty"`
+	Models        []*VehicleModel        `protobuf:"bytes,2,rep,name=models,proto3" json:"models,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListModelsResponse) Reset() {
+	*x = ListModelsResponse{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListModelsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListModelsResponse) ProtoMessage() {}
+
+func (x *ListModelsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListModelsResponse.ProtoReflect.Descriptor instead.
+func (*ListModelsResponse) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListModelsResponse) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *ListModelsResponse) GetModels() []*VehicleModel {
+	if x != nil {
+		return x.Models
+	}
+	return nil
+}
+
+type ResolveVehicleRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Names, aliases or near misses of them. Without a brand the model
+	// resolves only when a single brand has it.
+	Brand         string `protobuf:"bytes,1,opt,name=brand,proto3" json:"brand,omitempty"`
+	Model         string `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveVehicleRequest) Reset() {
+	*x = ResolveVehicleRequest{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveVehicleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveVehicleRequest) ProtoMessage() {}
+
+func (x *ResolveVehicleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveVehicleRequest.ProtoReflect.Descriptor instead.
+func (*ResolveVehicleRequest) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ResolveVehicleRequest) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *ResolveVehicleRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+type ResolveVehicleResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Brand string                 `protobuf:"bytes,1,opt,name=brand,proto3" json:"brand,omitempty"`
+	// Empty when only the brand resolved.
+	Model string `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	// 1 for names and aliases, lower for typos.
+	Score         float64 `protobuf:"fixed64,3,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResolveVehicleResponse) Reset() {
+	*x = ResolveVehicleResponse{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResolveVehicleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResolveVehicleResponse) ProtoMessage() {}
+
+func (x *ResolveVehicleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResolveVehicleResponse.ProtoReflect.Descriptor instead.
+func (*ResolveVehicleResponse) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ResolveVehicleResponse) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *ResolveVehicleResponse) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *ResolveVehicleResponse) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type SuggestVehiclesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// What the user typed so far; case, accents, spaces and a typo or two
+	// are ignored. Empty suggests the brands with the most listings.
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// 10 when unset, at most 50.
+	Limit         uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestVehiclesRequest) Reset() {
+	*x = SuggestVehiclesRequest{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestVehiclesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestVehiclesRequest) ProtoMessage() {}
+
+func (x *SuggestVehiclesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestVehiclesRequest.ProtoReflect.Descriptor instead.
+func (*SuggestVehiclesRequest) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SuggestVehiclesRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *SuggestVehiclesRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type VehicleSuggestion struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Brand string                 `protobuf:"bytes,1,opt,name=brand,proto3" json:"brand,omitempty"`
+	// Empty for brand suggestions.
+	Model string `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	// Listings seen lately of the brand or model.
+	Listings      uint32  `protobuf:"varint,3,opt,name=listings,proto3" json:"listings,omitempty"`
+	Score         float64 `protobuf:"fixed64,4,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VehicleSuggestion) Reset() {
+	*x = VehicleSuggestion{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VehicleSuggestion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VehicleSuggestion) ProtoMessage() {}
+
+func (x *VehicleSuggestion) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VehicleSuggestion.ProtoReflect.Descriptor instead.
+func (*VehicleSuggestion) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *VehicleSuggestion) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *VehicleSuggestion) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *VehicleSuggestion) GetListings() uint32 {
+	if x != nil {
+		return x.Listings
+	}
+	return 0
+}
+
+func (x *VehicleSuggestion) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type SuggestVehiclesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Suggestions   []*VehicleSuggestion   `protobuf:"bytes,1,rep,name=suggestions,proto3" json:"suggestions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SuggestVehiclesResponse) Reset() {
+	*x = SuggestVehiclesResponse{}
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SuggestVehiclesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SuggestVehiclesResponse) ProtoMessage() {}
+
+func (x *SuggestVehiclesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_autoscrapper_v1_autoscrapper_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SuggestVehiclesResponse.ProtoReflect.Descriptor instead.
+func (*SuggestVehiclesResponse) Descriptor() ([]byte, []int) {
+	return file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SuggestVehiclesResponse) GetSuggestions() []*VehicleSuggestion {
+	if x != nil {
+		return x.Suggestions
+	}
+	return nil
+}
+
+var File_autoscrapper_v1_autoscrapper_proto protoreflect.FileDescriptor
+
+const file_autoscrapper_v1_autoscrapper_proto_rawDesc = "" +
+	"\n" +
+	"\"autoscrapper/v1/autoscrapper.proto\x12\x0fautoscrapper.v1\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb1\x01\n" +
+	"\x13FindByFilterRequest\x12\x14\n" +
+	"\x05brand\x18\x01 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12\x19\n" +
+	"\bmin_year\x18\x03 \x01(\rR\aminYear\x12\x19\n" +
+	"\bmax_year\x18\x04 \x01(\rR\amaxYear\x12\x1b\n" +
+	"\tmin_price\x18\x05 \x01(\x01R\bminPrice\x12\x1b\n" +
+	"\tmax_price\x18\x06 \x01(\x01R\bmaxPrice\"\xdb\x02\n" +
+	"\x04Auto\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x01R\x05price\x12\x1b\n" +
+	"\timage_url\x18\x03 \x01(\tR\bimageUrl\x12\x10\n" +
+	"\x03url\x18\x04 \x01(\tR\x03url\x12\x1d\n" +
+	"\n" +
+	"image_urls\x18\x05 \x03(\tR\timageUrls\x12\x14\n" +
+	"\x05brand\x18\x06 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05model\x18\a \x01(\tR\x05model\x12\x12\n" +
+	"\x04year\x18\b \x01(\rR\x04year\x12\x1e\n" +
+	"\n" +
+	"kilometers\x18\t \x01(\rR\n" +
+	"kilometers\x12(\n" +
+	"\x10cached_image_url\x18\n" +
+	" \x01(\tR\x0ecachedImageUrl\x12*\n" +
+	"\x11cached_image_urls\x18\v \x03(\tR\x0fcachedImageUrls\x12#\n" +
+	"\rthumbnail_url\x18\f \x01(\tR\fthumbnailUrl\"\xc2\x01\n" +
+	"\x14FindByFilterResponse\x12+\n" +
+	"\x05autos\x18\x01 \x03(\v2\x15.autoscrapper.v1.AutoR\x05autos\x12:\n" +
+	"\bfailures\x18\x02 \x03(\v2\x1e.autoscrapper.v1.SourceFailureR\bfailures\x12A\n" +
+	"\rempty_scrapes\x18\x03 \x03(\v2\x1c.autoscrapper.v1.EmptyScrapeR\femptyScrapes\"\x98\x01\n" +
+	"\rSourceFailure\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x1b\n" +
+	"\tscrape_id\x18\x03 \x01(\tR\bscrapeId\x12:\n" +
+	"\vretry_delay\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"retryDelay\"B\n" +
+	"\vEmptyScrape\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x1b\n" +
+	"\tscrape_id\x18\x02 \x01(\tR\bscrapeId\"1\n" +
+	"\x17GetScrapeQualityRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\"n\n" +
+	"\fFieldQuality\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\x04R\x05found\x12\x18\n" +
+	"\amissing\x18\x03 \x01(\x04R\amissing\x12\x18\n" +
+	"\ainvalid\x18\x04 \x01(\x04R\ainvalid\"<\n" +
+	"\fSkippedItems\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x04R\x05count\"\xf6\x02\n" +
+	"\rSourceQuality\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x18\n" +
+	"\ascrapes\x18\x02 \x01(\x04R\ascrapes\x12\x12\n" +
+	"\x04seen\x18\x03 \x01(\x04R\x04seen\x12\x1c\n" +
+	"\textracted\x18\x04 \x01(\x04R\textracted\x12)\n" +
+	"\x10extraction_ratio\x18\x05 \x01(\x01R\x0fextractionRatio\x122\n" +
+	"\x15last_extraction_ratio\x18\x06 \x01(\x01R\x13lastExtractionRatio\x12\x1a\n" +
+	"\balerting\x18\a \x01(\bR\balerting\x12\x16\n" +
+	"\x06alerts\x18\b \x01(\x04R\x06alerts\x125\n" +
+	"\x06fields\x18\t \x03(\v2\x1d.autoscrapper.v1.FieldQualityR\x06fields\x127\n" +
+	"\askipped\x18\n" +
+	" \x03(\v2\x1d.autoscrapper.v1.SkippedItemsR\askipped\"T\n" +
+	"\x18GetScrapeQualityResponse\x128\n" +
+	"\asources\x18\x01 \x03(\v2\x1e.autoscrapper.v1.SourceQualityR\asources\"f\n" +
+	"\x15ListScrapeRunsRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x1f\n" +
+	"\verror_class\x18\x02 \x01(\tR\n" +
+	"errorClass\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\rR\x05limit\"\xeb\x03\n" +
+	"\tScrapeRun\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x18\n" +
+	"\abackend\x18\x03 \x01(\tR\abackend\x12\x16\n" +
+	"\x06filter\x18\x04 \x01(\tR\x06filter\x12\x10\n" +
+	"\x03url\x18\x05 \x01(\tR\x03url\x129\n" +
+	"\n" +
+	"started_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x125\n" +
+	"\bended_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aendedAt\x12\x14\n" +
+	"\x05pages\x18\b \x01(\rR\x05pages\x12\x12\n" +
+	"\x04seen\x18\t \x01(\rR\x04seen\x12\x14\n" +
+	"\x05found\x18\n" +
+	" \x01(\rR\x05found\x12\x18\n" +
+	"\askipped\x18\v \x01(\rR\askipped\x12\x14\n" +
+	"\x05bytes\x18\f \x01(\x04R\x05bytes\x12\x14\n" +
+	"\x05proxy\x18\r \x01(\tR\x05proxy\x12 \n" +
+	"\vfingerprint\x18\x0e \x01(\tR\vfingerprint\x12\x1f\n" +
+	"\verror_class\x18\x0f \x01(\tR\n" +
+	"errorClass\x12\x14\n" +
+	"\x05error\x18\x10 \x01(\tR\x05error\x12!\n" +
+	"\fartifacts_id\x18\x11 \x01(\tR\vartifactsId\"H\n" +
+	"\x16ListScrapeRunsResponse\x12.\n" +
+	"\x04runs\x18\x01 \x03(\v2\x1a.autoscrapper.v1.ScrapeRunR\x04runs\"B\n" +
+	"\x15ImportListingsRequest\x12)\n" +
+	"\x04auto\x18\x01 \x01(\v2\x15.autoscrapper.v1.AutoR\x04auto\"Q\n" +
+	"\x0fImportRejection\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\xc8\x01\n" +
+	"\x16ImportListingsResponse\x12\x1a\n" +
+	"\breceived\x18\x01 \x01(\rR\breceived\x12\x1a\n" +
+	"\bimported\x18\x02 \x01(\rR\bimported\x12\x18\n" +
+	"\aupdated\x18\x03 \x01(\rR\aupdated\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\x04 \x01(\rR\n" +
+	"duplicates\x12<\n" +
+	"\brejected\x18\x05 \x03(\v2 .autoscrapper.v1.ImportRejectionR\brejected\"J\n" +
+	"\x1aListRemovedListingsRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\"\xd5\x02\n" +
+	"\x10ListingLifecycle\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x01R\x05price\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"first_seen\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tfirstSeen\x127\n" +
+	"\tlast_seen\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x129\n" +
+	"\n" +
+	"removed_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tremovedAt\x12$\n" +
+	"\x0edays_on_market\x18\t \x01(\rR\fdaysOnMarket\"\\\n" +
+	"\x1bListRemovedListingsResponse\x12=\n" +
+	"\blistings\x18\x01 \x03(\v2!.autoscrapper.v1.ListingLifecycleR\blistings\"<\n" +
+	"\fVehicleModel\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aaliases\x18\x02 \x03(\tR\aaliases\"s\n" +
+	"\fVehicleBrand\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aaliases\x18\x02 \x03(\tR\aaliases\x125\n" +
+	"\x06models\x18\x03 \x03(\v2\x1d.autoscrapper.v1.VehicleModelR\x06models\"\x13\n" +
+	"\x11ListBrandsRequest\"K\n" +
+	"\x12ListBrandsResponse\x125\n" +
+	"\x06brands\x18\x01 \x03(\v2\x1d.autoscrapper.v1.VehicleBrandR\x06brands\")\n" +
+	"\x11ListModelsRequest\x12\x14\n" +
+	"\x05brand\x18\x01 \x01(\tR\x05brand\"a\n" +
+	"\x12ListModelsResponse\x12\x14\n" +
+	"\x05brand\x18\x01 \x01(\tR\x05brand\x125\n" +
+	"\x06models\x18\x02 \x03(\v2\x1d.autoscrapper.v1.VehicleModelR\x06models\"C\n" +
+	"\x15ResolveVehicleRequest\x12\x14\n" +
+	"\x05brand\x18\x01 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\"Z\n" +
+	"\x16ResolveVehicleResponse\x12\x14\n" +
+	"\x05brand\x18\x01 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x01R\x05score\"F\n" +
+	"\x16SuggestVehiclesRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\"q\n" +
+	"\x11VehicleSuggestion\x12\x14\n" +
+	"\x05brand\x18\x01 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12\x1a\n" +
+	"\blistings\x18\x03 \x01(\rR\blistings\x12\x14\n" +
+	"\x05score\x18\x04 \x01(\x01R\x05score\"_\n" +
+	"\x17SuggestVehiclesResponse\x12D\n" +
+	"\vsuggestions\x18\x01 \x03(\v2\".autoscrapper.v1.VehicleSuggestionR\vsuggestions2\x9e\a\n" +
+	"\x13AutoScrapperService\x12]\n" +
+	"\fFindByFilter\x12$.autoscrapper.v1.FindByFilterRequest\x1a%.autoscrapper.v1.FindByFilterResponse\"\x00\x12i\n" +
+	"\x10GetScrapeQuality\x12(.autoscrapper.v1.GetScrapeQualityRequest\x1a).autoscrapper.v1.GetScrapeQualityResponse\"\x00\x12c\n" +
+	"\x0eListScrapeRuns\x12&.autoscrapper.v1.ListScrapeRunsRequest\x1a'.autoscrapper.v1.ListScrapeRunsResponse\"\x00\x12e\n" +
+	"\x0eImportListings\x12&.autoscrapper.v1.ImportListingsRequest\x1a'.autoscrapper.v1.ImportListingsResponse\"\x00(\x01\x12r\n" +
+	"\x13ListRemovedListings\x12+.autoscrapper.v1.ListRemovedListingsRequest\x1a,.autoscrapper.v1.ListRemovedListingsResponse\"\x00\x12W\n" +
+	"\n" +
+	"ListBrands\x12\".autoscrapper.v1.ListBrandsRequest\x1a#.autoscrapper.v1.ListBrandsResponse\"\x00\x12W\n" +
+	"\n" +
+	"ListModels\x12\".autoscrapper.v1.ListModelsRequest\x1a#.autoscrapper.v1.ListModelsResponse\"\x00\x12c\n" +
+	"\x0eResolveVehicle\x12&.autoscrapper.v1.ResolveVehicleRequest\x1a'.autoscrapper.v1.ResolveVehicleResponse\"\x00\x12f\n" +
+	"\x0fSuggestVehicles\x12'.autoscrapper.v1.SuggestVehiclesRequest\x1a(.autoscrapper.v1.SuggestVehiclesResponse\"\x00B\xeb\x01\n" +
 	"\x13com.autoscrapper.v1B\x11AutoscrapperProtoP\x01Zdgithub.com/diegoafg1009/auto-radar-scraping-microservice/pkg/genproto/autoscrapper/v1;autoscrapperv1\xa2\x02\x03AXX\xaa\x02\x0fAutoscrapper.V1\xca\x02\x0fAutoscrapper\\V1\xe2\x02\x1bAutoscrapper\\V1\\GPBMetadata\xea\x02\x10Autoscrapper::V1b\x06proto3"
 
 var (
@@ -252,21 +2199,85 @@ func file_autoscrapper_v1_autoscrapper_proto_rawDescGZIP() []byte {
 	return file_autoscrapper_v1_autoscrapper_proto_rawDescData
 }
 
-var file_autoscrapper_v1_autoscrapper_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_autoscrapper_v1_autoscrapper_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_autoscrapper_v1_autoscrapper_proto_goTypes = []any{
-	(*FindByFilterRequest)(nil),  // 0: autoscrapper.v1.FindByFilterRequest
-	(*Auto)(nil),                 // 1: autoscrapper.v1.Auto
-	(*FindByFilterResponse)(nil), // 2: autoscrapper.v1.FindByFilterResponse
+	(*FindByFilterRequest)(nil),         // 0: autoscrapper.v1.FindByFilterRequest
+	(*Auto)(nil),                        // 1: autoscrapper.v1.Auto
+	(*FindByFilterResponse)(nil),        // 2: autoscrapper.v1.FindByFilterResponse
+	(*SourceFailure)(nil),               // 3: autoscrapper.v1.SourceFailure
+	(*EmptyScrape)(nil),                 // 4: autoscrapper.v1.EmptyScrape
+	(*GetScrapeQualityRequest)(nil),     // 5: autoscrapper.v1.GetScrapeQualityRequest
+	(*FieldQuality)(nil),                // 6: autoscrapper.v1.FieldQuality
+	(*SkippedItems)(nil),                // 7: autoscrapper.v1.SkippedItems
+	(*SourceQuality)(nil),               // 8: autoscrapper.v1.SourceQuality
+	(*GetScrapeQualityResponse)(nil),    // 9: autoscrapper.v1.GetScrapeQualityResponse
+	(*ListScrapeRunsRequest)(nil),       // 10: autoscrapper.v1.ListScrapeRunsRequest
+	(*ScrapeRun)(nil),                   // 11: autoscrapper.v1.ScrapeRun
+	(*ListScrapeRunsResponse)(nil),      // 12: autoscrapper.v1.ListScrapeRunsResponse
+	(*ImportListingsRequest)(nil),       // 13: autoscrapper.v1.ImportListingsRequest
+	(*ImportRejection)(nil),             // 14: autoscrapper.v1.ImportRejection
+	(*ImportListingsResponse)(nil),      // 15: autoscrapper.v1.ImportListingsResponse
+	(*ListRemovedListingsRequest)(nil),  // 16: autoscrapper.v1.ListRemovedListingsRequest
+	(*ListingLifecycle)(nil),            // 17: autoscrapper.v1.ListingLifecycle
+	(*ListRemovedListingsResponse)(nil), // 18: autoscrapper.v1.ListRemovedListingsResponse
+	(*VehicleModel)(nil),                // 19: autoscrapper.v1.VehicleModel
+	(*VehicleBrand)(nil),                // 20: autoscrapper.v1.VehicleBrand
+	(*ListBrandsRequest)(nil),           // 21: autoscrapper.v1.ListBrandsRequest
+	(*ListBrandsResponse)(nil),          // 22: autoscrapper.v1.ListBrandsResponse
+	(*ListModelsRequest)(nil),           // 23: autoscrapper.v1.ListModelsRequest
+	(*ListModelsResponse)(nil),          // 24: autoscrapper.v1.ListModelsResponse
+	(*ResolveVehicleRequest)(nil),       // 25: autoscrapper.v1.ResolveVehicleRequest
+	(*ResolveVehicleResponse)(nil),      // 26: autoscrapper.v1.ResolveVehicleResponse
+	(*SuggestVehiclesRequest)(nil),      // 27: autoscrapper.v1.SuggestVehiclesRequest
+	(*VehicleSuggestion)(nil),           // 28: autoscrapper.v1.VehicleSuggestion
+	(*SuggestVehiclesResponse)(nil),     // 29: autoscrapper.v1.SuggestVehiclesResponse
+	(*durationpb.Duration)(nil),         // 30: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),       // 31: google.protobuf.Timestamp
 }
 var file_autoscrapper_v1_autoscrapper_proto_depIdxs = []int32{
-	1, // 0: autoscrapper.v1.FindByFilterResponse.autos:type_name -> autoscrapper.v1.Auto
-	0, // 1: autoscrapper.v1.AutoScrapperService.FindByFilter:input_type -> autoscrapper.v1.FindByFilterRequest
-	2, // 2: autoscrapper.v1.AutoScrapperService.FindByFilter:output_type -> autoscrapper.v1.FindByFilterResponse
-	2, // [2:3] is the sub-list for method output_type
-	1, // [1:2] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	1,  // 0: autoscrapper.v1.FindByFilterResponse.autos:type_name -> autoscrapper.v1.Auto
+	3,  // 1: autoscrapper.v1.FindByFilterResponse.failures:type_name -> autoscrapper.v1.SourceFailure
+	4,  // 2: autoscrapper.v1.FindByFilterResponse.empty_scrapes:type_name -> autoscrapper.v1.EmptyScrape
+	30, // 3: autoscrapper.v1.SourceFailure.retry_delay:type_name -> google.protobuf.Duration
+	6,  // 4: autoscrapper.v1.SourceQuality.fields:type_name -> autoscrapper.v1.FieldQuality
+	7,  // 5: autoscrapper.v1.SourceQuality.skipped:type_name -> autoscrapper.v1.SkippedItems
+	8,  // 6: autoscrapper.v1.GetScrapeQualityResponse.sources:type_name -> autoscrapper.v1.SourceQuality
+	31, // 7: autoscrapper.v1.ScrapeRun.started_at:type_name -> google.protobuf.Timestamp
+	31, // 8: autoscrapper.v1.ScrapeRun.ended_at:type_name -> google.protobuf.Timestamp
+	11, // 9: autoscrapper.v1.ListScrapeRunsResponse.runs:type_name -> autoscrapper.v1.ScrapeRun
+	1,  // 10: autoscrapper.v1.ImportListingsRequest.auto:type_name -> autoscrapper.v1.Auto
+	14, // 11: autoscrapper.v1.ImportListingsResponse.rejected:type_name -> autoscrapper.v1.ImportRejection
+	31, // 12: autoscrapper.v1.ListingLifecycle.first_seen:type_name -> google.protobuf.Timestamp
+	31, // 13: autoscrapper.v1.ListingLifecycle.last_seen:type_name -> google.protobuf.Timestamp
+	31, // 14: autoscrapper.v1.ListingLifecycle.removed_at:type_name -> google.protobuf.Timestamp
+	17, // 15: autoscrapper.v1.ListRemovedListingsResponse.listings:type_name -> autoscrapper.v1.ListingLifecycle
+	19, // 16: autoscrapper.v1.VehicleBrand.models:type_name -> autoscrapper.v1.VehicleModel
+	20, // 17: autoscrapper.v1.ListBrandsResponse.brands:type_name -> autoscrapper.v1.VehicleBrand
+	19, // 18: autoscrapper.v1.ListModelsResponse.models:type_name -> autoscrapper.v1.VehicleModel
+	28, // 19: autoscrapper.v1.SuggestVehiclesResponse.suggestions:type_name -> autoscrapper.v1.VehicleSuggestion
+	0,  // 20: autoscrapper.v1.AutoScrapperService.FindByFilter:input_type -> autoscrapper.v1.FindByFilterRequest
+	5,  // 21: autoscrapper.v1.AutoScrapperService.GetScrapeQuality:input_type -> autoscrapper.v1.GetScrapeQualityRequest
+	10, // 22: autoscrapper.v1.AutoScrapperService.ListScrapeRuns:input_type -> autoscrapper.v1.ListScrapeRunsRequest
+	13, // 23: autoscrapper.v1.AutoScrapperService.ImportListings:input_type -> autoscrapper.v1.ImportListingsRequest
+	16, // 24: autoscrapper.v1.AutoScrapperService.ListRemovedListings:input_type -> autoscrapper.v1.ListRemovedListingsRequest
+	21, // 25: autoscrapper.v1.AutoScrapperService.ListBrands:input_type -> autoscrapper.v1.ListBrandsRequest
+	23, // 26: autoscrapper.v1.AutoScrapperService.ListModels:input_type -> autoscrapper.v1.ListModelsRequest
+	25, // 27: autoscrapper.v1.AutoScrapperService.ResolveVehicle:input_type -> autoscrapper.v1.ResolveVehicleRequest
+	27, // 28: autoscrapper.v1.AutoScrapperService.SuggestVehicles:input_type -> autoscrapper.v1.SuggestVehiclesRequest
+	2,  // 29: autoscrapper.v1.AutoScrapperService.FindByFilter:output_type -> autoscrapper.v1.FindByFilterResponse
+	9,  // 30: autoscrapper.v1.AutoScrapperService.GetScrapeQuality:output_type -> autoscrapper.v1.GetScrapeQualityResponse
+	12, // 31: autoscrapper.v1.AutoScrapperService.ListScrapeRuns:output_type -> autoscrapper.v1.ListScrapeRunsResponse
+	15, // 32: autoscrapper.v1.AutoScrapperService.ImportListings:output_type -> autoscrapper.v1.ImportListingsResponse
+	18, // 33: autoscrapper.v1.AutoScrapperService.ListRemovedListings:output_type -> autoscrapper.v1.ListRemovedListingsResponse
+	22, // 34: autoscrapper.v1.AutoScrapperService.ListBrands:output_type -> autoscrapper.v1.ListBrandsResponse
+	24, // 35: autoscrapper.v1.AutoScrapperService.ListModels:output_type -> autoscrapper.v1.ListModelsResponse
+	26, // 36: autoscrapper.v1.AutoScrapperService.ResolveVehicle:output_type -> autoscrapper.v1.ResolveVehicleResponse
+	29, // 37: autoscrapper.v1.AutoScrapperService.SuggestVehicles:output_type -> autoscrapper.v1.SuggestVehiclesResponse
+	29, // [29:38] is the sub-list for method output_type
+	20, // [20:29] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_autoscrapper_v1_autoscrapper_proto_init() }
@@ -280,7 +2291,7 @@ func file_autoscrapper_v1_autoscrapper_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_autoscrapper_v1_autoscrapper_proto_rawDesc), len(file_autoscrapper_v1_autoscrapper_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -36,11 +36,43 @@ const (
 	// AutoScrapperServiceFindByFilterProcedure is the fully-qualified name of the AutoScrapperService's
 	// FindByFilter RPC.
 	AutoScrapperServiceFindByFilterProcedure = "/autoscrapper.v1.AutoScrapperService/FindByFilter"
+	// AutoScrapperServiceGetScrapeQualityProcedure is the fully-qualified name of the
+	// AutoScrapperService's GetScrapeQuality RPC.
+	AutoScrapperServiceGetScrapeQualityProcedure = "/autoscrapper.v1.AutoScrapperService/GetScrapeQuality"
+	// AutoScrapperServiceListScrapeRunsProcedure is the fully-qualified name of the
+	// AutoScrapperService's ListScrapeRuns RPC.
+	AutoScrapperServiceListScrapeRunsProcedure = "/autoscrapper.v1.AutoScrapperService/ListScrapeRuns"
+	// AutoScrapperServiceImportListingsProcedure is the fully-qualified name of the
+	// AutoScrapperService's ImportListings RPC.
+	AutoScrapperServiceImportListingsProcedure = "/autoscrapper.v1.AutoScrapperService/ImportListings"
+	// AutoScrapperServiceListRemovedListingsProcedure is the fully-qualified name of the
+	// AutoScrapperService's ListRemovedListings RPC.
+	AutoScrapperServiceListRemovedListingsProcedure = "/autoscrapper.v1.AutoScrapperService/ListRemovedListings"
+	// AutoScrapperServiceListBrandsProcedure is the fully-qualified name of the AutoScrapperService's
+	// ListBrands RPC.
+	AutoScrapperServiceListBrandsProcedure = "/autoscrapper.v1.AutoScrapperService/ListBrands"
+	// AutoScrapperServiceListModelsProcedure is the fully-qualified name of the AutoScrapperService's
+	// ListModels RPC.
+	AutoScrapperServiceListModelsProcedure = "/autoscrapper.v1.AutoScrapperService/ListModels"
+	// AutoScrapperServiceResolveVehicleProcedure is the fully-qualified name of the
+	// AutoScrapperService's ResolveVehicle RPC.
+	AutoScrapperServiceResolveVehicleProcedure = "/autoscrapper.v1.AutoScrapperService/ResolveVehicle"
+	// AutoScrapperServiceSuggestVehiclesProcedure is the fully-qualified name of the
+	// AutoScrapperService's SuggestVehicles RPC.
+	AutoScrapperServiceSuggestVehiclesProcedure = "/autoscrapper.v1.AutoScrapperService/SuggestVehicles"
 )
 
 // AutoScrapperServiceClient is a client for the autoscrapper.v1.AutoScrapperService service.
 type AutoScrapperServiceClient interface {
 	FindByFilter(context.Context, *connect.Request[v1.FindByFilterRequest]) (*connect.Response[v1.FindByFilterResponse], error)
+	GetScrapeQuality(context.Context, *connect.Request[v1.GetScrapeQualityRequest]) (*connect.Response[v1.GetScrapeQualityResponse], error)
+	ListScrapeRuns(context.Context, *connect.Request[v1.ListScrapeRunsRequest]) (*connect.Response[v1.ListScrapeRunsResponse], error)
+	ImportListings(context.Context) *connect.ClientStreamForClient[v1.ImportListingsRequest, v1.ImportListingsResponse]
+	ListRemovedListings(context.Context, *connect.Request[v1.ListRemovedListingsRequest]) (*connect.Response[v1.ListRemovedListingsResponse], error)
+	ListBrands(context.Context, *connect.Request[v1.ListBrandsRequest]) (*connect.Response[v1.ListBrandsResponse], error)
+	ListModels(context.Context, *connect.Request[v1.ListModelsRequest]) (*connect.Response[v1.ListModelsResponse], error)
+	ResolveVehicle(context.Context, *connect.Request[v1.ResolveVehicleRequest]) (*connect.Response[v1.ResolveVehicleResponse], error)
+	SuggestVehicles(context.Context, *connect.Request[v1.SuggestVehiclesRequest]) (*connect.Response[v1.SuggestVehiclesResponse], error)
 }
 
 // NewAutoScrapperServiceClient constructs a client for the autoscrapper.v1.AutoScrapperService
@@ -60,12 +92,68 @@ func NewAutoScrapperServiceClient(httpClient connect.HTTPClient, baseURL string,
 			connect.WithSchema(autoScrapperServiceMethods.ByName("FindByFilter")),
 			connect.WithClientOptions(opts...),
 		),
+		getScrapeQuality: connect.NewClient[v1.GetScrapeQualityRequest, v1.GetScrapeQualityResponse](
+			httpClient,
+			baseURL+AutoScrapperServiceGetScrapeQualityProcedure,
+			connect.WithSchema(autoScrapperServiceMethods.ByName("GetScrapeQuality")),
+			connect.WithClientOptions(opts...),
+		),
+		listScrapeRuns: connect.NewClient[v1.ListScrapeRunsRequest, v1.ListScrapeRunsResponse](
+			httpClient,
+			baseURL+AutoScrapperServiceListScrapeRunsProcedure,
+			connect.WithSchema(autoScrapperServiceMethods.ByName("ListScrapeRuns")),
+			connect.WithClientOptions(opts...),
+		),
+		importListings: connect.NewClient[v1.ImportListingsRequest, v1.ImportListingsResponse](
+			httpClient,
+			baseURL+AutoScrapperServiceImportListingsProcedure,
+			connect.WithSchema(autoScrapperServiceMethods.ByName("ImportListings")),
+			connect.WithClientOptions(opts...),
+		),
+		listRemovedListings: connect.NewClient[v1.ListRemovedListingsRequest, v1.ListRemovedListingsResponse](
+			httpClient,
+			baseURL+AutoScrapperServiceListRemovedListingsProcedure,
+			connect.WithSchema(autoScrapperServiceMethods.ByName("ListRemovedListings")),
+			connect.WithClientOptions(opts...),
+		),
+		listBrands: connect.NewClient[v1.ListBrandsRequest, v1.ListBrandsResponse](
+			httpClient,
+			baseURL+AutoScrapperServiceListBrandsProcedure,
+			connect.WithSchema(autoScrapperServiceMethods.ByName("ListBrands")),
+			connect.WithClientOptions(opts...),
+		),
+		listModels: connect.NewClient[v1.ListModelsRequest, v1.ListModelsResponse](
+			httpClient,
+			baseURL+AutoScrapperServiceListModelsProcedure,
+			connect.WithSchema(autoScrapperServiceMethods.ByName("ListModels")),
+			connect.WithClientOptions(opts...),
+		),
+		resolveVehicle: connect.NewClient[v1.ResolveVehicleRequest, v1.ResolveVehicleResponse](
+			httpClient,
+			baseURL+AutoScrapperServiceResolveVehicleProcedure,
+			connect.WithSchema(autoScrapperServiceMethods.ByName("ResolveVehicle")),
+			connect.WithClientOptions(opts...),
+		),
+		suggestVehicles: connect.NewClient[v1.SuggestVehiclesRequest, v1.SuggestVehiclesResponse](
+			httpClient,
+			baseURL+AutoScrapperServiceSuggestVehiclesProcedure,
+			connect.WithSchema(autoScrapperServiceMethods.ByName("SuggestVehicles")),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // autoScrapperServiceClient implements AutoScrapperServiceClient.
 type autoScrapperServiceClient struct {
-	findByFilter *connect.Client[v1.FindByFilterRequest, v1.FindByFilterResponse]
+	findByFilter        *connect.Client[v1.FindByFilterRequest, v1.FindByFilterResponse]
+	getScrapeQuality    *connect.Client[v1.GetScrapeQualityRequest, v1.GetScrapeQualityResponse]
+	listScrapeRuns      *connect.Client[v1.ListScrapeRunsRequest, v1.ListScrapeRunsResponse]
+	importListings      *connect.Client[v1.ImportListingsRequest, v1.ImportListingsResponse]
+	listRemovedListings *connect.Client[v1.ListRemovedListingsRequest, v1.ListRemovedListingsResponse]
+	listBrands          *connect.Client[v1.ListBrandsRequest, v1.ListBrandsResponse]
+	listModels          *connect.Client[v1.ListModelsRequest, v1.ListModelsResponse]
+	resolveVehicle      *connect.Client[v1.ResolveVehicleRequest, v1.ResolveVehicleResponse]
+	suggestVehicles     *connect.Client[v1.SuggestVehiclesRequest, v1.SuggestVehiclesResponse]
 }
 
 // FindByFilter calls autoscrapper.v1.AutoScrapperService.FindByFilter.
@@ -73,10 +161,58 @@ func (c *autoScrapperServiceClient) FindByFilter(ctx context.Context, req *conne
 	return c.findByFilter.CallUnary(ctx, req)
 }
 
+// GetScrapeQuality calls autoscrapper.v1.AutoScrapperService.GetScrapeQuality.
+func (c *autoScrapperServiceClient) GetScrapeQuality(ctx context.Context, req *connect.Request[v1.GetScrapeQualityRequest]) (*connect.Response[v1.GetScrapeQualityResponse], error) {
+	return c.getScrapeQuality.CallUnary(ctx, req)
+}
+
+// ListScrapeRuns calls autoscrapper.v1.AutoScrapperService.ListScrapeRuns.
+func (c *autoScrapperServiceClient) ListScrapeRuns(ctx context.Context, req *connect.Request[v1.ListScrapeRunsRequest]) (*connect.Response[v1.ListScrapeRunsResponse], error) {
+	return c.listScrapeRuns.CallUnary(ctx, req)
+}
+
+// ImportListings calls autoscrapper.v1.AutoScrapperService.ImportListings.
+func (c *autoScrapperServiceClient) ImportListings(ctx context.Context) *connect.ClientStreamForClient[v1.ImportListingsRequest, v1.ImportListingsResponse] {
+	return c.importListings.CallClientStream(ctx)
+}
+
+// ListRemovedListings calls autoscrapper.v1.AutoScrapperService.ListRemovedListings.
+func (c *autoScrapperServiceClient) ListRemovedListings(ctx context.Context, req *connect.Request[v1.ListRemovedListingsRequest]) (*connect.Response[v1.ListRemovedListingsResponse], error) {
+	return c.listRemovedListings.CallUnary(ctx, req)
+}
+
+// ListBrands calls autoscrapper.v1.AutoScrapperService.ListBrands.
+func (c *autoScrapperServiceClient) ListBrands(ctx context.Context, req *connect.Request[v1.ListBrandsRequest]) (*connect.Response[v1.ListBrandsResponse], error) {
+	return c.listBrands.CallUnary(ctx, req)
+}
+
+// ListModels calls autoscrapper.v1.AutoScrapperService.ListModels.
+func (c *autoScrapperServiceClient) ListModels(ctx context.Context, req *connect.Request[v1.ListModelsRequest]) (*connect.Response[v1.ListModelsResponse], error) {
+	return c.listModels.CallUnary(ctx, req)
+}
+
+// ResolveVehicle calls autoscrapper.v1.AutoScrapperService.ResolveVehicle.
+func (c *autoScrapperServiceClient) ResolveVehicle(ctx context.Context, req *connect.Request[v1.ResolveVehicleRequest]) (*connect.Response[v1.ResolveVehicleResponse], error) {
+	return c.resolveVehicle.CallUnary(ctx, req)
+}
+
+// SuggestVehicles calls autoscrapper.v1.AutoScrapperService.SuggestVehicles.
+func (c *autoScrapperServiceClient) SuggestVehicles(ctx context.Context, req *connect.Request[v1.SuggestVehiclesRequest]) (*connect.Response[v1.SuggestVehiclesResponse], error) {
+	return c.suggestVehicles.CallUnary(ctx, req)
+}
+
 // AutoScrapperServiceHandler is an implementation of the autoscrapper.v1.AutoScrapperService
 // service.
 type AutoScrapperServiceHandler interface {
 	FindByFilter(context.Context, *connect.Request[v1.FindByFilterRequest]) (*connect.Response[v1.FindByFilterResponse], error)
+	GetScrapeQuality(context.Context, *connect.Request[v1.GetScrapeQualityRequest]) (*connect.Response[v1.GetScrapeQualityResponse], error)
+	ListScrapeRuns(context.Context, *connect.Request[v1.ListScrapeRunsRequest]) (*connect.Response[v1.ListScrapeRunsResponse], error)
+	ImportListings(context.Context, *connect.ClientStream[v1.ImportListingsRequest]) (*connect.Response[v1.ImportListingsResponse], error)
+	ListRemovedListings(context.Context, *connect.Request[v1.ListRemovedListingsRequest]) (*connect.Response[v1.ListRemovedListingsResponse], error)
+	ListBrands(context.Context, *connect.Request[v1.ListBrandsRequest]) (*connect.Response[v1.ListBrandsResponse], error)
+	ListModels(context.Context, *connect.Request[v1.ListModelsRequest]) (*connect.Response[v1.ListModelsResponse], error)
+	ResolveVehicle(context.Context, *connect.Request[v1.ResolveVehicleRequest]) (*connect.Response[v1.ResolveVehicleResponse], error)
+	SuggestVehicles(context.Context, *connect.Request[v1.SuggestVehiclesRequest]) (*connect.Response[v1.SuggestVehiclesResponse], error)
 }
 
 // NewAutoScrapperServiceHandler builds an HTTP handler from the service implementation. It returns
@@ -92,10 +228,74 @@ func NewAutoScrapperServiceHandler(svc AutoScrapperServiceHandler, opts ...conne
 		connect.WithSchema(autoScrapperServiceMethods.ByName("FindByFilter")),
 		connect.WithHandlerOptions(opts...),
 	)
+	autoScrapperServiceGetScrapeQualityHandler := connect.NewUnaryHandler(
+		AutoScrapperServiceGetScrapeQualityProcedure,
+		svc.GetScrapeQuality,
+		connect.WithSchema(autoScrapperServiceMethods.ByName("GetScrapeQuality")),
+		connect.WithHandlerOptions(opts...),
+	)
+	autoScrapperServiceListScrapeRunsHandler := connect.NewUnaryHandler(
+		AutoScrapperServiceListScrapeRunsProcedure,
+		svc.ListScrapeRuns,
+		connect.WithSchema(autoScrapperServiceMethods.ByName("ListScrapeRuns")),
+		connect.WithHandlerOptions(opts...),
+	)
+	autoScrapperServiceImportListingsHandler := connect.NewClientStreamHandler(
+		AutoScrapperServiceImportListingsProcedure,
+		svc.ImportListings,
+		connect.WithSchema(autoScrapperServiceMethods.ByName("ImportListings")),
+		connect.WithHandlerOptions(opts...),
+	)
+	autoScrapperServiceListRemovedListingsHandler := connect.NewUnaryHandler(
+		AutoScrapperServiceListRemovedListingsProcedure,
+		svc.ListRemovedListings,
+		connect.WithSchema(autoScrapperServiceMethods.ByName("ListRemovedListings")),
+		connect.WithHandlerOptions(opts...),
+	)
+	autoScrapperServiceListBrandsHandler := connect.NewUnaryHandler(
+		AutoScrapperServiceListBrandsProcedure,
+		svc.ListBrands,
+		connect.WithSchema(autoScrapperServiceMethods.ByName("ListBrands")),
+		connect.WithHandlerOptions(opts...),
+	)
+	autoScrapperServiceListModelsHandler := connect.NewUnaryHandler(
+		AutoScrapperServiceListModelsProcedure,
+		svc.ListModels,
+		connect.WithSchema(autoScrapperServiceMethods.ByName("ListModels")),
+		connect.WithHandlerOptions(opts...),
+	)
+	autoScrapperServiceResolveVehicleHandler := connect.NewUnaryHandler(
+		AutoScrapperServiceResolveVehicleProcedure,
+		svc.ResolveVehicle,
+		connect.WithSchema(autoScrapperServiceMethods.ByName("ResolveVehicle")),
+		connect.WithHandlerOptions(opts...),
+	)
+	autoScrapperServiceSuggestVehiclesHandler := connect.NewUnaryHandler(
+		AutoScrapperServiceSuggestVehiclesProcedure,
+		svc.SuggestVehicles,
+		connect.WithSchema(autoScrapperServiceMethods.ByName("SuggestVehicles")),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/autoscrapper.v1.AutoScrapperService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case AutoScrapperServiceFindByFilterProcedure:
 			autoScrapperServiceFindByFilterHandler.ServeHTTP(w, r)
+		case AutoScrapperServiceGetScrapeQualityProcedure:
+			autoScrapperServiceGetScrapeQualityHandler.ServeHTTP(w, r)
+		case AutoScrapperServiceListScrapeRunsProcedure:
+			autoScrapperServiceListScrapeRunsHandler.ServeHTTP(w, r)
+		case AutoScrapperServiceImportListingsProcedure:
+			autoScrapperServiceImportListingsHandler.ServeHTTP(w, r)
+		case AutoScrapperServiceListRemovedListingsProcedure:
+			autoScrapperServiceListRemovedListingsHandler.ServeHTTP(w, r)
+		case AutoScrapperServiceListBrandsProcedure:
+			autoScrapperServiceListBrandsHandler.ServeHTTP(w, r)
+		case AutoScrapperServiceListModelsProcedure:
+			autoScrapperServiceListModelsHandler.ServeHTTP(w, r)
+		case AutoScrapperServiceResolveVehicleProcedure:
+			autoScrapperServiceResolveVehicleHandler.ServeHTTP(w, r)
+		case AutoScrapperServiceSuggestVehiclesProcedure:
+			autoScrapperServiceSuggestVehiclesHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -108,3 +308,35 @@ type UnimplementedAutoScrapperServiceHandler struct{}
 func (UnimplementedAutoScrapperServiceHandler) FindByFilter(context.Context, *connect.Request[v1.FindByFilterRequest]) (*connect.Response[v1.FindByFilterResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("autoscrapper.v1.AutoScrapperService.FindByFilter is not implemented"))
 }
+
+func (UnimplementedAutoScrapperServiceHandler) GetScrapeQuality(context.Context, *connect.Request[v1.GetScrapeQualityRequest]) (*connect.Response[v1.GetScrapeQualityResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("autoscrapper.v1.AutoScrapperService.GetScrapeQuality is not implemented"))
+}
+
+func (UnimplementedAutoScrapperServiceHandler) ListScrapeRuns(context.Context, *connect.Request[v1.ListScrapeRunsRequest]) (*connect.Response[v1.ListScrapeRunsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("autoscrapper.v1.AutoScrapperService.ListScrapeRuns is not implemented"))
+}
+
+func (UnimplementedAutoScrapperServiceHandler) ImportListings(context.Context, *connect.ClientStream[v1.ImportListingsRequest]) (*connect.Response[v1.ImportListingsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("autoscrapper.v1.AutoScrapperService.ImportListings is not implemented"))
+}
+
+func (UnimplementedAutoScrapperServiceHandler) ListRemovedListings(context.Context, *connect.Request[v1.ListRemovedListingsRequest]) (*connect.Response[v1.ListRemovedListingsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("autoscrapper.v1.AutoScrapperService.ListRemovedListings is not implemented"))
+}
+
+func (UnimplementedAutoScrapperServiceHandler) ListBrands(context.Context, *connect.Request[v1.ListBrandsRequest]) (*connect.Response[v1.ListBrandsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("autoscrapper.v1.AutoScrapperService.ListBrands is not implemented"))
+}
+
+func (UnimplementedAutoScrapperServiceHandler) ListModels(context.Context, *connect.Request[v1.ListModelsRequest]) (*connect.Response[v1.ListModelsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("autoscrapper.v1.AutoScrapperService.ListModels is not implemented"))
+}
+
+func (UnimplementedAutoScrapperServiceHandler) ResolveVehicle(context.Context, *connect.Request[v1.ResolveVehicleRequest]) (*connect.Response[v1.ResolveVehicleResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("autoscrapper.v1.AutoScrapperService.ResolveVehicle is not implemented"))
+}
+
+func (UnimplementedAutoScrapperServiceHandler) SuggestVehicles(context.Context, *connect.Request[v1.SuggestVehiclesRequest]) (*connect.Response[v1.SuggestVehiclesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("autoscrapper.v1.AutoScrapperService.SuggestVehicles is not implemented"))
+}